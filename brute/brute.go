@@ -1,8 +1,13 @@
 package brute
 
 import (
+	"container/heap"
+	"context"
 	"errors"
+	"fmt"
 	"slices"
+	"sync/atomic"
+	"time"
 )
 
 type METHOD string
@@ -18,6 +23,12 @@ type State interface {
 	Done() bool
 }
 
+// WeightedState state with cost of edge reaching it, used by FindDijkstra
+type WeightedState interface {
+	State
+	Cost() float64
+}
+
 func NewStep[S State](s S, lastStep *Step[S]) *Step[S] {
 	if lastStep == nil {
 		return &Step[S]{State: s}
@@ -26,6 +37,7 @@ func NewStep[S State](s S, lastStep *Step[S]) *Step[S] {
 		State: s,
 
 		cost:   lastStep.cost + 1,
+		weight: lastStep.weight + 1,
 		parent: lastStep,
 	}
 }
@@ -33,7 +45,8 @@ func NewStep[S State](s S, lastStep *Step[S]) *Step[S] {
 type Step[S State] struct {
 	State S
 
-	cost     int
+	cost     int     // hops from root
+	weight   float64 // accumulated edge costs from root, same as cost unless found by FindDijkstra
 	parent   *Step[S]
 	children []*Step[S]
 }
@@ -51,80 +64,240 @@ func (s *Step[S]) Backtrack() (steps []*Step[S]) {
 }
 func (s *Step[S]) Cost() int { return s.cost }
 
+// Weight return accumulated WeightedState costs from root, found by FindDijkstra, same as Cost otherwise
+func (s *Step[S]) Weight() float64 { return s.weight }
+
+// Depth return count of ancestors
+func (s *Step[S]) Depth() (depth int) {
+	for ; s.parent != nil; s = s.parent {
+		depth++
+	}
+	return depth
+}
+
+// Root return initial step
+func (s *Step[S]) Root() *Step[S] {
+	for s != nil && s.parent != nil {
+		s = s.parent
+	}
+	return s
+}
+
+// Path return states from root to current step
+func (s *Step[S]) Path() (states []S) {
+	for _, step := range s.Backtrack() {
+		states = append(states, step.State)
+	}
+	return states
+}
+
 func (s *Step[S]) visited(key string) bool {
 	return key == s.State.Key() || (s.parent != nil && s.parent.visited(key))
 }
 
-func NewBruter[S State](processor func(S) []S) *Bruter[S] {
-	return &Bruter[S]{
+func NewBruter[S State](processor func(S) []S, opts ...BruterOption[S]) *Bruter[S] {
+	b := &Bruter[S]{
 		steps:   make(map[string]*Step[S]),
 		process: processor,
 	}
+	for _, opt := range opts {
+		b = opt(b)
+	}
+	return b
+}
+
+// NewBruterWithStore return bruter records visited states in store instead of in memory steps
+func NewBruterWithStore[S State](processor func(S) []S, store VisitedStore, opts ...BruterOption[S]) *Bruter[S] {
+	b := NewBruter(processor, opts...)
+	b.steps, b.visited = nil, store
+	return b
+}
+
+// BruterOption bruter option
+type BruterOption[S State] func(*Bruter[S]) *Bruter[S]
+
+// WithPruning set prune function, states pruned will not be explored, neither their children
+func WithPruning[S State](fn func(S) bool) BruterOption[S] {
+	return func(b *Bruter[S]) *Bruter[S] {
+		b.prune = fn
+		return b
+	}
+}
+
+// WithMaxSolutions set max solutions FindAll returns, zero means unlimited
+func WithMaxSolutions[S State](n int) BruterOption[S] {
+	return func(b *Bruter[S]) *Bruter[S] {
+		b.maxSolutions = n
+		return b
+	}
 }
 
 type Bruter[S State] struct {
-	steps map[string]*Step[S]
+	steps   map[string]*Step[S] // visited steps, nil when visited states recorded in external store
+	visited VisitedStore        // external store of visited states
+
+	process func(S) []S  // process state to next state
+	prune   func(S) bool // prune state and its children when return true
+
+	maxSolutions int // max solutions FindAll returns, zero means unlimited
+}
+
+// Find find the first solution, return ctx.Err() when ctx is done before search finished
+func (b Bruter[S]) Find(ctx context.Context, state S, method METHOD) (finalStep *Step[S], err error) {
+	finalSteps, err := b.find(ctx, state, method, 1)
+	if len(finalSteps) == 0 {
+		return nil, err
+	}
+	return finalSteps[0], err
+}
+
+// FindWithTimeout find the first solution, return *SearchTimeoutError if search not finished in timeout
+func (b Bruter[S]) FindWithTimeout(state S, method METHOD, timeout time.Duration) (*Step[S], error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
 
-	process func(S) []S // process state to next state
+	var visited int64
+	process := b.process
+	b.process = func(s S) []S {
+		atomic.AddInt64(&visited, 1)
+		return process(s)
+	}
+
+	type result struct {
+		step *Step[S]
+		err  error
+	}
+	ch := make(chan result, 1)
+	go func() {
+		step, err := b.Find(ctx, state, method)
+		ch <- result{step, err}
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case r := <-ch:
+		return r.step, r.err
+	case <-timer.C:
+		select {
+		case r := <-ch: // finished just as timer fired
+			return r.step, r.err
+		default:
+		}
+		cancel()
+		if r := <-ch; !errors.Is(r.err, context.Canceled) { // finished before noticing cancel
+			return r.step, r.err
+		}
+		return nil, &SearchTimeoutError{Timeout: timeout, StatesVisited: int(atomic.LoadInt64(&visited))}
+	}
+}
+
+// SearchTimeoutError search not finished in time
+type SearchTimeoutError struct {
+	Timeout       time.Duration
+	StatesVisited int // states processed before timeout
+}
+
+func (e *SearchTimeoutError) Error() string {
+	return fmt.Sprintf("search timeout after %s, %d states visited", e.Timeout, e.StatesVisited)
+}
+
+func (e *SearchTimeoutError) Unwrap() error { return context.DeadlineExceeded }
+
+// FindAll find all solutions instead of the first one
+// BFS returns all minimum-cost solutions, DFS returns all solutions in DFS order
+func (b Bruter[S]) FindAll(ctx context.Context, state S, method METHOD) (finalSteps []*Step[S], err error) {
+	return b.find(ctx, state, method, b.maxSolutions)
 }
 
-func (b Bruter[S]) Find(state S, method METHOD) (finalStep *Step[S], err error) {
+// find search solutions, stop searching when got limit solutions, zero means unlimited
+func (b Bruter[S]) find(ctx context.Context, state S, method METHOD, limit int) (finalSteps []*Step[S], err error) {
 	if err := state.Preprocess(); err != nil {
 		return nil, err
 	}
+	if b.pruned(state) {
+		return nil, nil
+	}
+
+	found := func(step *Step[S]) (stop bool) {
+		finalSteps = append(finalSteps, step)
+		return limit > 0 && len(finalSteps) >= limit
+	}
 	switch method {
 	case DFS:
-		return b.dfs(NewStep[S](state, nil)), nil
+		_, err = b.dfs(ctx, NewStep[S](state, nil), found)
 	case BFS:
-		return b.bfs(NewStep[S](state, nil)), nil
+		err = b.bfs(ctx, NewStep[S](state, nil), found)
 	default:
 		return nil, errors.New("unknown method")
 	}
+	return finalSteps, err
 }
 
-func (b Bruter[S]) dfs(s *Step[S]) (finalStep *Step[S]) {
+func (b Bruter[S]) dfs(ctx context.Context, s *Step[S], found func(*Step[S]) bool) (stop bool, err error) {
+	if err := ctx.Err(); err != nil {
+		return true, err
+	}
 	for _, nextState := range b.process(s.State) {
 		key := nextState.Key()
 
-		if s.visited(key) || b.steps[key] != nil {
+		if s.visited(key) || b.pruned(nextState) {
 			continue
 		}
 
 		nextStep := NewStep(nextState, s)
-		b.steps[key] = nextStep
+		if !b.visit(key, nextStep) {
+			continue
+		}
 		s.children = append(s.children, nextStep)
 
 		if nextState.Done() {
-			return nextStep
+			if found(nextStep) {
+				return true, nil
+			}
+			continue
 		}
 
-		if step := b.dfs(nextStep); step != nil {
-			return step
+		if stop, err := b.dfs(ctx, nextStep, found); stop {
+			return true, err
 		}
 	}
-	return nil
+	return false, nil
 }
 
-func (b Bruter[S]) bfs(s *Step[S]) (finalStep *Step[S]) {
+func (b Bruter[S]) bfs(ctx context.Context, s *Step[S], found func(*Step[S]) bool) error {
 	var queue Queue[S]
 	queue.Enqueue(s)
-	for !queue.Empty() {
+	for minCost := -1; !queue.Empty(); {
 		var steps []*Step[S]
 
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
 		s = queue.Dequeue()
+		if minCost >= 0 && s.cost >= minCost { // all minimum-cost solutions found
+			return nil
+		}
 		for _, nextState := range b.process(s.State) {
 			key := nextState.Key()
 
-			if s.visited(key) || b.steps[key] != nil {
+			if s.visited(key) || b.pruned(nextState) {
 				continue
 			}
 
 			nextStep := NewStep(nextState, s)
-			b.steps[key] = nextStep
+			if !b.visit(key, nextStep) {
+				continue
+			}
 			s.children = append(s.children, nextStep)
 
 			if nextState.Done() {
-				return nextStep
+				minCost = nextStep.cost
+				if found(nextStep) {
+					return nil
+				}
+				continue
 			}
 
 			steps = append(steps, nextStep)
@@ -134,11 +307,167 @@ func (b Bruter[S]) bfs(s *Step[S]) (finalStep *Step[S]) {
 	return nil
 }
 
+// FindBeam find solution with beam search, only beamWidth states with highest score are kept at each level
+// beam search is not complete, solution may be missed if beamWidth is too small, zero means unlimited
+func (b Bruter[S]) FindBeam(ctx context.Context, state S, beamWidth int, score func(S) float64) (*Step[S], error) {
+	if err := state.Preprocess(); err != nil {
+		return nil, err
+	}
+	if b.pruned(state) {
+		return nil, nil
+	}
+
+	for beam := []*Step[S]{NewStep[S](state, nil)}; len(beam) > 0; {
+		var next []*Step[S]
+		for _, s := range beam {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			for _, nextState := range b.process(s.State) {
+				key := nextState.Key()
+
+				if s.visited(key) || b.pruned(nextState) {
+					continue
+				}
+
+				nextStep := NewStep(nextState, s)
+				if !b.visit(key, nextStep) {
+					continue
+				}
+				s.children = append(s.children, nextStep)
+
+				if nextState.Done() {
+					return nextStep, nil
+				}
+				next = append(next, nextStep)
+			}
+		}
+
+		slices.SortStableFunc(next, func(x, y *Step[S]) int {
+			switch sx, sy := score(x.State), score(y.State); {
+			case sx > sy:
+				return -1
+			case sx < sy:
+				return 1
+			default:
+				return 0
+			}
+		})
+		if beamWidth > 0 && len(next) > beamWidth {
+			next = next[:beamWidth]
+		}
+		beam = next
+	}
+	return nil, nil
+}
+
+// FindDijkstra find solution with minimum accumulated cost, cost of state is WeightedState.Cost(), 1 if not implemented,
+// accumulated cost is Step.Weight, return error when negative cost found
+func (b Bruter[S]) FindDijkstra(ctx context.Context, state S) (*Step[S], error) {
+	if err := state.Preprocess(); err != nil {
+		return nil, err
+	}
+	if b.pruned(state) {
+		return nil, nil
+	}
+
+	best := map[string]float64{state.Key(): 0} // min cost found of states not settled
+	frontier := &stepHeap[S]{NewStep[S](state, nil)}
+	for frontier.Len() > 0 {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		s := heap.Pop(frontier).(*Step[S])
+		key := s.State.Key()
+		if s.weight > best[key] { // outdated entry, cheaper one popped before
+			continue
+		}
+		if s.parent != nil {
+			if !b.visit(key, s) { // settled
+				continue
+			}
+			s.parent.children = append(s.parent.children, s)
+			if s.State.Done() {
+				return s, nil
+			}
+		}
+
+		for _, nextState := range b.process(s.State) {
+			nextKey := nextState.Key()
+			if s.visited(nextKey) || b.pruned(nextState) {
+				continue
+			}
+
+			cost := 1.0
+			if ws, ok := any(nextState).(WeightedState); ok {
+				cost = ws.Cost()
+			}
+			if cost < 0 {
+				return nil, fmt.Errorf("negative cost %g of state %s", cost, nextKey)
+			}
+			if w, ok := best[nextKey]; ok && w <= s.weight+cost {
+				continue
+			}
+			best[nextKey] = s.weight + cost
+			heap.Push(frontier, &Step[S]{State: nextState, cost: s.cost + 1, weight: s.weight + cost, parent: s})
+		}
+	}
+	return nil, nil
+}
+
+// stepHeap min-heap of steps by weight
+type stepHeap[S State] []*Step[S]
+
+func (h stepHeap[S]) Len() int            { return len(h) }
+func (h stepHeap[S]) Less(i, j int) bool  { return h[i].weight < h[j].weight }
+func (h stepHeap[S]) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *stepHeap[S]) Push(x interface{}) { *h = append(*h, x.(*Step[S])) }
+func (h *stepHeap[S]) Pop() interface{} {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
+
+// Steps return visited steps by state key, nil when bruter created with external store
+func (b Bruter[S]) Steps() map[string]*Step[S] { return b.steps }
+
+// Neighbors return next states of state without recording them as visited, for inspecting search space
+func (b Bruter[S]) Neighbors(state S) []S { return b.process(state) }
+
+// VisitedCount return number of visited states, -1 if external store unable to tell
+func (b Bruter[S]) VisitedCount() int {
+	if b.visited == nil {
+		return len(b.steps)
+	}
+	if store, ok := b.visited.(interface{ Len() int }); ok {
+		return store.Len()
+	}
+	return -1
+}
+
+// visit record step as visited, return false if visited before
+func (b Bruter[S]) visit(key string, step *Step[S]) bool {
+	if b.visited != nil {
+		return b.visited.Add(key)
+	}
+	if b.steps[key] != nil {
+		return false
+	}
+	b.steps[key] = step
+	return true
+}
+
+func (b Bruter[S]) pruned(s S) bool { return b.prune != nil && b.prune(s) }
+
+// Queue queue of steps waiting to be explored in BFS
 type Queue[S State] struct {
 	queue []*Step[S]
 }
 
 func (q *Queue[S]) Empty() bool { return len(q.queue) == 0 }
+func (q *Queue[S]) Len() int    { return len(q.queue) }
 func (q *Queue[S]) Enqueue(steps ...*Step[S]) {
 	q.queue = append(q.queue, steps...)
 }
@@ -151,3 +480,14 @@ func (q *Queue[S]) Dequeue() *Step[S] {
 	q.queue = q.queue[1:]
 	return s
 }
+
+// Peek return front step without dequeueing, nil if empty
+func (q *Queue[S]) Peek() *Step[S] {
+	if len(q.queue) == 0 {
+		return nil
+	}
+	return q.queue[0]
+}
+
+// Clear empty queue
+func (q *Queue[S]) Clear() { q.queue = nil }
@@ -1 +1,430 @@
 package brute
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// graph node -> next nodes
+var testGraph = map[string][]string{
+	"a": {"b", "c"},
+	"b": {"d"},
+	"c": {"e"},
+	"d": {"goal"},
+	"e": {"goal"},
+}
+
+type node struct{ name string }
+
+func (n node) Key() string       { return n.name }
+func (n node) Preprocess() error { return nil }
+func (n node) Done() bool        { return strings.HasPrefix(n.name, "goal") }
+
+// newGraphProcessor return processor walking through graph, every processed state is recorded in visited
+func newGraphProcessor(graph map[string][]string, visited map[string]int) func(node) []node {
+	return func(n node) (next []node) {
+		visited[n.name]++
+		for _, name := range graph[n.name] {
+			next = append(next, node{name: name})
+		}
+		return next
+	}
+}
+
+func TestBruter_WithPruning(t *testing.T) {
+	for _, method := range []METHOD{BFS, DFS} {
+		visited := make(map[string]int)
+		bruter := NewBruter(newGraphProcessor(testGraph, visited),
+			WithPruning(func(n node) bool { return n.name == "b" }))
+
+		step, err := bruter.Find(context.Background(), node{name: "a"}, method)
+		if err != nil {
+			t.Errorf("%s: find fail: %s", method, err)
+			continue
+		}
+		if step == nil {
+			t.Errorf("%s: expect solution, got nil", method)
+			continue
+		}
+
+		if expected := "a,c,e,goal"; pathOf(step) != expected {
+			t.Errorf("%s: unexpected path: %s, expect: %s", method, pathOf(step), expected)
+		}
+		for _, name := range []string{"b", "d"} {
+			if visited[name] != 0 {
+				t.Errorf("%s: pruned state %s visited", method, name)
+			}
+		}
+	}
+}
+
+func TestBruter_WithPruning_root(t *testing.T) {
+	visited := make(map[string]int)
+	bruter := NewBruter(newGraphProcessor(testGraph, visited),
+		WithPruning(func(n node) bool { return n.name == "a" }))
+
+	step, err := bruter.Find(context.Background(), node{name: "a"}, BFS)
+	if err != nil {
+		t.Errorf("find fail: %s", err)
+	}
+	if step != nil || len(visited) != 0 {
+		t.Errorf("pruned root should not be explored, got step %v, visited %v", step, visited)
+	}
+}
+
+func pathOf(step *Step[node]) string {
+	var names []string
+	for _, s := range step.Backtrack() {
+		names = append(names, s.State.name)
+	}
+	return strings.Join(names, ",")
+}
+
+func TestBruter_FindAll(t *testing.T) {
+	graph := map[string][]string{
+		"a": {"b", "c", "goal1"},
+		"b": {"goal2", "d"},
+		"c": {"goal3"},
+		"d": {"goal4"},
+	}
+
+	for _, testcase := range []struct {
+		method       METHOD
+		maxSolutions int
+		expected     []string
+	}{
+		{BFS, 0, []string{"a,goal1"}},
+		{DFS, 0, []string{"a,b,goal2", "a,b,d,goal4", "a,c,goal3", "a,goal1"}},
+		{DFS, 2, []string{"a,b,goal2", "a,b,d,goal4"}},
+	} {
+		bruter := NewBruter(newGraphProcessor(graph, make(map[string]int)), WithMaxSolutions[node](testcase.maxSolutions))
+		steps, err := bruter.FindAll(context.Background(), node{name: "a"}, testcase.method)
+		if err != nil {
+			t.Errorf("%s: find all fail: %s", testcase.method, err)
+			continue
+		}
+
+		var paths []string
+		for _, step := range steps {
+			paths = append(paths, pathOf(step))
+		}
+		if strings.Join(paths, " ") != strings.Join(testcase.expected, " ") {
+			t.Errorf("%s(max %d): unexpected solutions: %v, expect: %v",
+				testcase.method, testcase.maxSolutions, paths, testcase.expected)
+		}
+	}
+}
+
+func TestBruter_FindAll_minimumCost(t *testing.T) {
+	graph := map[string][]string{
+		"a": {"b", "c"},
+		"b": {"goal1", "d"},
+		"c": {"goal2"},
+		"d": {"goal3"},
+	}
+
+	steps, err := NewBruter(newGraphProcessor(graph, make(map[string]int))).FindAll(context.Background(), node{name: "a"}, BFS)
+	if err != nil {
+		t.Errorf("find all fail: %s", err)
+		return
+	}
+
+	var paths []string
+	for _, step := range steps {
+		paths = append(paths, pathOf(step))
+	}
+	if expected := "a,b,goal1 a,c,goal2"; strings.Join(paths, " ") != expected {
+		t.Errorf("unexpected solutions: %v, expect: %s", paths, expected)
+	}
+}
+
+func TestBruter_Find_cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	for _, method := range []METHOD{BFS, DFS} {
+		visited := make(map[string]int)
+		step, err := NewBruter(newGraphProcessor(testGraph, visited)).Find(ctx, node{name: "a"}, method)
+		if err != context.Canceled {
+			t.Errorf("%s: expect context canceled error, got: %v", method, err)
+		}
+		if step != nil || len(visited) != 0 {
+			t.Errorf("%s: cancelled search should not explore any state, got step %v, visited %v", method, step, visited)
+		}
+	}
+}
+
+// countingStore map store counting Add calls
+type countingStore struct {
+	VisitedStore
+	adds, hits int
+}
+
+func (s *countingStore) Add(key string) bool {
+	s.adds++
+	if !s.VisitedStore.Add(key) {
+		s.hits++
+		return false
+	}
+	return true
+}
+
+func TestNewBruterWithStore(t *testing.T) {
+	for _, method := range []METHOD{BFS, DFS} {
+		store := &countingStore{VisitedStore: NewMapStore()}
+		visited := make(map[string]int)
+		bruter := NewBruterWithStore(newGraphProcessor(testGraph, visited), store)
+
+		step, err := bruter.Find(context.Background(), node{name: "a"}, method)
+		if err != nil || step == nil {
+			t.Errorf("%s: expect solution, got step %v, err %v", method, step, err)
+			continue
+		}
+		if step.Cost() != 3 {
+			t.Errorf("%s: unexpected solution: %s", method, pathOf(step))
+		}
+		for name, n := range visited {
+			if n > 1 {
+				t.Errorf("%s: state %s processed %d times", method, name, n)
+			}
+		}
+		if store.adds == 0 {
+			t.Errorf("%s: store not used", method)
+		}
+	}
+
+	// goal reachable from both d and e, second one is deduplicated by store
+	store := &countingStore{VisitedStore: NewMapStore()}
+	if _, err := NewBruterWithStore(newGraphProcessor(testGraph, make(map[string]int)), store, WithMaxSolutions[node](0)).
+		FindAll(context.Background(), node{name: "a"}, DFS); err != nil {
+		t.Errorf("find all fail: %s", err)
+	}
+	if store.adds != 6 || store.hits != 1 {
+		t.Errorf("expect 6 adds with 1 hit, got %d adds with %d hits", store.adds, store.hits)
+	}
+}
+
+func TestBruter_FindWithTimeout(t *testing.T) {
+	// endless graph without goal
+	endless := func(n node) []node { return []node{{name: n.name + "a"}, {name: n.name + "b"}} }
+
+	for _, method := range []METHOD{BFS, DFS} {
+		start := time.Now()
+		step, err := NewBruter(endless).FindWithTimeout(node{name: "x"}, method, 50*time.Millisecond)
+
+		var timeoutErr *SearchTimeoutError
+		if !errors.As(err, &timeoutErr) {
+			t.Errorf("%s: expect search timeout error, got: %v", method, err)
+			continue
+		}
+		if step != nil || timeoutErr.StatesVisited == 0 || !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("%s: unexpected result: step %v, err %v", method, step, err)
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("%s: search not stopped in time, took %s", method, elapsed)
+		}
+	}
+
+	step, err := NewBruter(newGraphProcessor(testGraph, make(map[string]int))).FindWithTimeout(node{name: "a"}, BFS, time.Second)
+	if err != nil || pathOf(step) != "a,b,d,goal" {
+		t.Errorf("unexpected result: step %v, err %v", step, err)
+	}
+	// search finished after timer fired but before cancel noticed, result is kept
+	slow := func(n node) []node {
+		time.Sleep(50 * time.Millisecond)
+		return []node{{name: "goal"}}
+	}
+	for _, method := range []METHOD{BFS, DFS} {
+		step, err := NewBruter(slow).FindWithTimeout(node{name: "x"}, method, 10*time.Millisecond)
+		if err != nil || pathOf(step) != "x,goal" {
+			t.Errorf("%s: expect finished result kept, got step %v, err %v", method, step, err)
+		}
+	}
+}
+
+func TestStep_Depth(t *testing.T) {
+	root := NewStep(node{name: "a"}, nil)
+	b := NewStep(node{name: "b"}, root)
+	c := NewStep(node{name: "c"}, b)
+
+	for step, expected := range map[*Step[node]]int{root: 0, b: 1, c: 2} {
+		if depth := step.Depth(); depth != expected {
+			t.Errorf("%s: expect depth %d, got %d", step.State.name, expected, depth)
+		}
+		if step.Root() != root {
+			t.Errorf("%s: unexpected root %v", step.State.name, step.Root())
+		}
+	}
+
+	if path := c.Path(); len(path) != 3 || path[0].name != "a" || path[2].name != "c" {
+		t.Errorf("unexpected path: %v", path)
+	}
+
+	step, _ := NewBruter(newGraphProcessor(testGraph, make(map[string]int))).Find(context.Background(), node{name: "a"}, BFS)
+	if step.Depth() != 3 || step.Depth() != len(step.Path())-1 {
+		t.Errorf("unexpected depth %d of path %s", step.Depth(), pathOf(step))
+	}
+}
+
+func TestBruter_FindBeam(t *testing.T) {
+	graph := map[string][]string{
+		"a": {"b", "c"},
+		"b": {"d"},
+		"c": {"e", "f"},
+		"d": {"g"},
+		"e": {"goal"},
+		"g": {"goal"},
+	}
+	// prefer alphabetically smaller states
+	score := func(n node) float64 { return -float64(n.name[0]) }
+
+	expected, err := NewBruter(newGraphProcessor(graph, make(map[string]int))).Find(context.Background(), node{name: "a"}, BFS)
+	if err != nil || expected == nil {
+		t.Fatalf("bfs find fail: step %v, err %v", expected, err)
+	}
+
+	step, err := NewBruter(newGraphProcessor(graph, make(map[string]int))).FindBeam(context.Background(), node{name: "a"}, 3, score)
+	if err != nil || step == nil || pathOf(step) != pathOf(expected) {
+		t.Errorf("beam search with full width should find bfs solution %s, got step %v, err %v", pathOf(expected), step, err)
+	}
+
+	// beam of width 1 keeps only b at first level, so the longer path is found
+	visited := make(map[string]int)
+	step, err = NewBruter(newGraphProcessor(graph, visited)).FindBeam(context.Background(), node{name: "a"}, 1, score)
+	if err != nil || step == nil || pathOf(step) != "a,b,d,g,goal" {
+		t.Errorf("unexpected beam search result: step %v, err %v", step, err)
+	}
+	if visited["c"] != 0 {
+		t.Errorf("state out of beam should not be expanded")
+	}
+}
+
+func TestQueue(t *testing.T) {
+	var queue Queue[node]
+	a, b := NewStep(node{name: "a"}, nil), NewStep(node{name: "b"}, nil)
+
+	if queue.Len() != 0 || queue.Peek() != nil {
+		t.Errorf("expect empty queue")
+	}
+	queue.Enqueue(a, b)
+	if queue.Len() != 2 || queue.Peek() != a {
+		t.Errorf("expect 2 steps with a in front, got %d", queue.Len())
+	}
+	if queue.Dequeue() != a || queue.Len() != 1 || queue.Peek() != b {
+		t.Errorf("expect 1 step with b in front after dequeue, got %d", queue.Len())
+	}
+	queue.Clear()
+	if queue.Len() != 0 || !queue.Empty() || queue.Dequeue() != nil {
+		t.Errorf("expect empty queue after clear")
+	}
+}
+
+func TestBruter_Steps(t *testing.T) {
+	bruter := NewBruter(newGraphProcessor(testGraph, make(map[string]int)))
+	if _, err := bruter.FindAll(context.Background(), node{name: "a"}, DFS); err != nil {
+		t.Fatalf("find all fail: %s", err)
+	}
+
+	steps := bruter.Steps()
+	if len(steps) != 5 {
+		t.Errorf("expect 5 visited steps, got %d", len(steps))
+	}
+	if step := steps["goal"]; step == nil || pathOf(step) != "a,b,d,goal" {
+		t.Errorf("unexpected step of goal: %v", step)
+	}
+
+	if NewBruterWithStore(newGraphProcessor(testGraph, nil), NewMapStore()).Steps() != nil {
+		t.Errorf("bruter with external store should not record steps")
+	}
+}
+
+func TestBruter_Neighbors(t *testing.T) {
+	visited := make(map[string]int)
+	bruter := NewBruter(newGraphProcessor(testGraph, visited))
+
+	var names []string
+	for _, n := range bruter.Neighbors(node{name: "a"}) {
+		names = append(names, n.name)
+	}
+	if strings.Join(names, ",") != "b,c" {
+		t.Errorf("unexpected neighbors of a: %v", names)
+	}
+	if len(bruter.Neighbors(node{name: "goal"})) != 0 {
+		t.Errorf("goal should have no neighbors")
+	}
+	if bruter.VisitedCount() != 0 {
+		t.Errorf("neighbors should not record visited states, got %d", bruter.VisitedCount())
+	}
+
+	if _, err := bruter.FindAll(context.Background(), node{name: "a"}, DFS); err != nil {
+		t.Fatalf("find all fail: %s", err)
+	}
+	if n := bruter.VisitedCount(); n != 5 {
+		t.Errorf("expect 5 visited states, got %d", n)
+	}
+
+	withStore := NewBruterWithStore(newGraphProcessor(testGraph, make(map[string]int)), NewMapStore())
+	if _, err := withStore.FindAll(context.Background(), node{name: "a"}, DFS); err != nil {
+		t.Fatalf("find all fail: %s", err)
+	}
+	if n := withStore.VisitedCount(); n != 5 {
+		t.Errorf("expect 5 visited states in map store, got %d", n)
+	}
+	if n := NewBruterWithStore(newGraphProcessor(testGraph, nil), &countingStore{VisitedStore: NewMapStore()}).VisitedCount(); n != -1 {
+		t.Errorf("expect -1 for store without Len, got %d", n)
+	}
+}
+
+// weightedNode node with cost of edge reaching it
+type weightedNode struct {
+	node
+	cost float64
+}
+
+func (n weightedNode) Cost() float64 { return n.cost }
+
+func TestBruter_FindDijkstra(t *testing.T) {
+	// a -> b -> goal costs 11 with 2 hops, a -> c -> d -> goal costs 3 with 3 hops
+	graph := map[string][]weightedNode{
+		"a": {{node{"b"}, 1}, {node{"c"}, 1}},
+		"b": {{node{"goal"}, 10}},
+		"c": {{node{"d"}, 1}},
+		"d": {{node{"goal"}, 1}},
+	}
+	processor := func(n weightedNode) []weightedNode { return graph[n.name] }
+	pathOf := func(step *Step[weightedNode]) string {
+		var names []string
+		for _, s := range step.Path() {
+			names = append(names, s.name)
+		}
+		return strings.Join(names, ",")
+	}
+
+	step, err := NewBruter(processor).Find(context.Background(), weightedNode{node: node{"a"}}, BFS)
+	if err != nil || pathOf(step) != "a,b,goal" {
+		t.Fatalf("bfs should find minimum-hop path, got step %v, err %v", step, err)
+	}
+
+	bruter := NewBruter(processor)
+	step, err = bruter.FindDijkstra(context.Background(), weightedNode{node: node{"a"}})
+	if err != nil || step == nil {
+		t.Fatalf("dijkstra find fail: step %v, err %v", step, err)
+	}
+	if pathOf(step) != "a,c,d,goal" || step.Weight() != 3 || step.Cost() != 3 || step.Depth() != 3 {
+		t.Errorf("expect minimum-cost path a,c,d,goal with weight 3, got %s with weight %g", pathOf(step), step.Weight())
+	}
+
+	graph["d"][0].cost = -1
+	if _, err := NewBruter(processor).FindDijkstra(context.Background(), weightedNode{node: node{"a"}}); err == nil {
+		t.Errorf("expect error of negative cost")
+	}
+
+	// unweighted states cost 1 each
+	unweighted, err := NewBruter(newGraphProcessor(testGraph, make(map[string]int))).FindDijkstra(context.Background(), node{name: "a"})
+	if err != nil || unweighted == nil || unweighted.Weight() != 3 || unweighted.Cost() != 3 {
+		t.Errorf("unexpected result of unweighted graph: step %v, err %v", unweighted, err)
+	}
+}
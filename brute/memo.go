@@ -0,0 +1,50 @@
+package brute
+
+import (
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// NewMemoizedBruter return bruter caching next states of processor by state key,
+// processor is called at most once for states with same key
+func NewMemoizedBruter[S State](processor func(S) []S, opts ...BruterOption[S]) *MemoizedBruter[S] {
+	m := &MemoizedBruter[S]{processor: processor}
+	m.Bruter = NewBruter(m.process, opts...)
+	return m
+}
+
+// MemoizedBruter bruter caching result of processor
+type MemoizedBruter[S State] struct {
+	*Bruter[S]
+
+	processor func(S) []S
+	cache     sync.Map // state key -> *memoEntry[S]
+
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+// CacheStats return count of processing served by cache and by processor
+func (m *MemoizedBruter[S]) CacheStats() (hits, misses int64) {
+	return m.hits.Load(), m.misses.Load()
+}
+
+func (m *MemoizedBruter[S]) process(s S) []S {
+	value, loaded := m.cache.LoadOrStore(s.Key(), new(memoEntry[S]))
+	if loaded {
+		m.hits.Add(1)
+	} else {
+		m.misses.Add(1)
+	}
+
+	entry := value.(*memoEntry[S])
+	entry.once.Do(func() { entry.next = m.processor(s) })
+	return slices.Clone(entry.next)
+}
+
+// memoEntry next states of a state, processed once even if requested concurrently
+type memoEntry[S State] struct {
+	once sync.Once
+	next []S
+}
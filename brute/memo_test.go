@@ -0,0 +1,50 @@
+package brute
+
+import (
+	"context"
+	"sync"
+	"testing"
+)
+
+func TestNewMemoizedBruter(t *testing.T) {
+	graph := map[string][]string{
+		"a": {"b", "c"},
+		"b": {"d"},
+		"c": {"d"},
+		"d": {"goal"},
+	}
+	var mu sync.Mutex
+	visited := make(map[string]int)
+	processor := newGraphProcessor(graph, visited)
+	bruter := NewMemoizedBruter(func(n node) []node {
+		mu.Lock()
+		defer mu.Unlock()
+		return processor(n)
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for _, name := range []string{"a", "b", "c", "d"} {
+				bruter.Neighbors(node{name: name})
+			}
+		}()
+	}
+	wg.Wait()
+
+	step, err := bruter.Find(context.Background(), node{name: "a"}, BFS)
+	if err != nil || step == nil || pathOf(step) != "a,b,d,goal" {
+		t.Fatalf("unexpected solution: %v, err: %v", step, err)
+	}
+
+	for name, count := range visited {
+		if count != 1 {
+			t.Errorf("expect %s processed once, got %d", name, count)
+		}
+	}
+	if hits, misses := bruter.CacheStats(); hits != 40 || misses != 4 { // 4 states processed by search are all cached
+		t.Errorf("unexpected cache stats: %d hits, %d misses, %d states processed", hits, misses, len(visited))
+	}
+}
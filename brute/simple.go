@@ -0,0 +1,45 @@
+package brute
+
+import "fmt"
+
+var _ State = (*SimpleState[int])(nil)
+
+// NewSimpleState return state wrapping val, done and neighbors are shared with all states derived from it,
+// solve it with NewBruter(SimpleProcessor[T]) without defining custom state type
+func NewSimpleState[T comparable](val T, done func(T) bool, neighbors func(T) []T) *SimpleState[T] {
+	return &SimpleState[T]{Value: val, done: done, neighbors: neighbors}
+}
+
+// SimpleState adapter implementing State by functions over value
+type SimpleState[T comparable] struct {
+	Value T
+
+	done      func(T) bool
+	neighbors func(T) []T
+}
+
+// Key return value formatted by %v
+func (s *SimpleState[T]) Key() string { return fmt.Sprintf("%v", s.Value) }
+
+// Preprocess do nothing
+func (s *SimpleState[T]) Preprocess() error { return nil }
+
+// Done report whether value is a solution
+func (s *SimpleState[T]) Done() bool { return s.done != nil && s.done(s.Value) }
+
+// Neighbors return next states of value
+func (s *SimpleState[T]) Neighbors() []*SimpleState[T] {
+	if s.neighbors == nil {
+		return nil
+	}
+
+	values := s.neighbors(s.Value)
+	states := make([]*SimpleState[T], 0, len(values))
+	for _, val := range values {
+		states = append(states, NewSimpleState(val, s.done, s.neighbors))
+	}
+	return states
+}
+
+// SimpleProcessor processor of SimpleState for NewBruter
+func SimpleProcessor[T comparable](s *SimpleState[T]) []*SimpleState[T] { return s.Neighbors() }
@@ -0,0 +1,55 @@
+package brute
+
+import (
+	"context"
+	"testing"
+)
+
+func TestSimpleState_maze(t *testing.T) {
+	type point struct{ x, y int }
+	maze := []string{
+		"S.#.",
+		".##.",
+		"...#",
+		"#.#G",
+		"...#",
+	}
+	var start, goal point
+	for y, row := range maze {
+		for x, c := range row {
+			switch c {
+			case 'S':
+				start = point{x, y}
+			case 'G':
+				goal = point{x, y}
+			}
+		}
+	}
+
+	state := NewSimpleState(start, func(p point) bool { return p == goal }, func(p point) (next []point) {
+		for _, d := range []point{{1, 0}, {-1, 0}, {0, 1}, {0, -1}} {
+			n := point{p.x + d.x, p.y + d.y}
+			if n.y >= 0 && n.y < len(maze) && n.x >= 0 && n.x < len(maze[n.y]) && maze[n.y][n.x] != '#' {
+				next = append(next, n)
+			}
+		}
+		return next
+	})
+
+	step, err := NewBruter(SimpleProcessor[point]).Find(context.Background(), state, BFS)
+	if err == nil && step != nil {
+		t.Fatalf("expect goal unreachable, got path: %v", step.Path())
+	}
+
+	maze[3] = "#..G"
+	step, err = NewBruter(SimpleProcessor[point]).Find(context.Background(), state, BFS)
+	if err != nil || step == nil {
+		t.Fatalf("find path fail: %v", err)
+	}
+	if step.State.Value != goal || step.Cost() != 6 {
+		t.Errorf("expect shortest path of 6 steps to %v, got %v", goal, step.Path())
+	}
+	if key := step.State.Key(); key != "{3 3}" {
+		t.Errorf("unexpected key: %s", key)
+	}
+}
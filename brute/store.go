@@ -0,0 +1,24 @@
+package brute
+
+// VisitedStore store keys of visited states
+// implement it with redis or bloom filter for distributed or memory-constrained search,
+// store may implement Len() int to report number of keys for Bruter.VisitedCount
+type VisitedStore interface {
+	// Add add key to store, return false if key already present
+	Add(key string) bool
+}
+
+// NewMapStore return a VisitedStore backed by map
+func NewMapStore() VisitedStore { return make(mapStore) }
+
+type mapStore map[string]struct{}
+
+func (m mapStore) Add(key string) bool {
+	if _, ok := m[key]; ok {
+		return false
+	}
+	m[key] = struct{}{}
+	return true
+}
+
+func (m mapStore) Len() int { return len(m) }
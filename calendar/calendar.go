@@ -2,6 +2,9 @@ package calendar
 
 import (
 	"bytes"
+	"fmt"
+	"io"
+	"net/url"
 	"time"
 )
 
@@ -47,8 +50,83 @@ func NewCalendar(name, desc string, opts ...CalendarOption) *Calendar {
 
 func (c *Calendar) AddEvents(events ...Event) { c.events = append(c.events, events...) }
 
+// EventByUID return event with uid
+func (c *Calendar) EventByUID(uid string) (*Event, bool) {
+	for i := range c.events {
+		if c.events[i].uid == UID(uid) {
+			return &c.events[i], true
+		}
+	}
+	return nil, false
+}
+
+// MergeWith return a new calendar contains events of both calendars, with c's properties
+// events with same uid are deduplicated, the one with later LAST-MODIFIED wins
+func (c *Calendar) MergeWith(other *Calendar) *Calendar {
+	merged := *c
+	merged.events = make([]Event, 0, len(c.events)+len(other.events))
+
+	index := make(map[UID]int) // uid -> index in merged events
+	for _, events := range [][]Event{c.events, other.events} {
+		for _, event := range events {
+			if event.uid == "" {
+				merged.events = append(merged.events, event)
+				continue
+			}
+			if i, ok := index[event.uid]; ok {
+				if event.modifiedAt.After(merged.events[i].modifiedAt.Time) {
+					merged.events[i] = event
+				}
+				continue
+			}
+			index[event.uid] = len(merged.events)
+			merged.events = append(merged.events, event)
+		}
+	}
+	return &merged
+}
+
+// FindConflicts return all pairs of overlapping events
+func (c *Calendar) FindConflicts() (conflicts [][2]Event) {
+	for i := range c.events {
+		for j := i + 1; j < len(c.events); j++ {
+			if c.events[i].Overlaps(c.events[j]) {
+				conflicts = append(conflicts, [2]Event{c.events[i], c.events[j]})
+			}
+		}
+	}
+	return conflicts
+}
+
 func (c *Calendar) Output() []byte {
 	var buf bytes.Buffer
+	_, _ = c.WriteTo(&buf)
+	return buf.Bytes()
+}
+
+// WriteTo write calendar to w component by component, without buffering whole output like Output
+func (c *Calendar) WriteTo(w io.Writer) (n int64, err error) {
+	write := func(p []byte) {
+		if err != nil {
+			return
+		}
+		var written int
+		written, err = w.Write(p)
+		n += int64(written)
+	}
+
+	write(c.headerOutput())
+	for i := range c.events {
+		write(c.events[i].Output())
+	}
+	write(c.tailer.Output())
+
+	return n, err
+}
+
+// headerOutput output calendar lines before events
+func (c *Calendar) headerOutput() []byte {
+	var buf bytes.Buffer
 
 	buf.Write(c.header.Output())
 	buf.WriteByte('\n')
@@ -81,13 +159,13 @@ func (c *Calendar) Output() []byte {
 		buf.Write(c.desc.Output())
 		buf.WriteByte('\n')
 	}
-
-	for _, event := range c.events {
-		buf.Write(event.Output())
+	if c.timeZone != "" {
+		if vtimezone := generateVTIMEZONE(c.timeZone); vtimezone != nil {
+			buf.Write(vtimezone)
+			buf.WriteByte('\n')
+		}
 	}
 
-	buf.Write(c.tailer.Output())
-
 	return buf.Bytes()
 }
 
@@ -112,16 +190,61 @@ func NewEvent(sum, description string, start time.Time, opts ...EventOption) *Ev
 	return e
 }
 
+// NewEventWithValidation build new calendar event like NewEvent, return error if event properties are invalid,
+// e.g. url is not absolute
+func NewEventWithValidation(sum, description string, start time.Time, opts ...EventOption) (*Event, error) {
+	e := NewEvent(sum, description, start, opts...)
+	if err := e.validate(); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// NewAllDayEvent build all-day event on date like DTSTART;VALUE=DATE:20250101,
+// only year, month and day of date in its location are used
+func NewAllDayEvent(sum, description string, date time.Time, opts ...EventOption) *Event {
+	return NewEvent(sum, description, allDay(date), append([]EventOption{SetStartFormat(LayoutDate, DateFormat)}, opts...)...)
+}
+
+// allDay return midnight in UTC of date in its location, so date is kept when output in UTC
+func allDay(date time.Time) time.Time {
+	return time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// NewRecurringEvent return event repeats every interval freq for count times, count not positive means unlimited
+func NewRecurringEvent(sum, description string, start time.Time, freq RRuleFreq, interval int, count int, opts ...EventOption) *Event {
+	return NewEvent(sum, description, start, append([]EventOption{WithRRule(RRule{Freq: freq, Interval: interval, Count: count})}, opts...)...)
+}
+
+// NewDailyEvent return event repeats every day for count times
+func NewDailyEvent(sum, description string, start time.Time, count int, opts ...EventOption) *Event {
+	return NewRecurringEvent(sum, description, start, FreqDaily, 1, count, opts...)
+}
+
+// NewWeeklyEvent return event repeats every week for count times
+func NewWeeklyEvent(sum, description string, start time.Time, count int, opts ...EventOption) *Event {
+	return NewRecurringEvent(sum, description, start, FreqWeekly, 1, count, opts...)
+}
+
+// NewMonthlyEvent return event repeats every month for count times
+func NewMonthlyEvent(sum, description string, start time.Time, count int, opts ...EventOption) *Event {
+	return NewRecurringEvent(sum, description, start, FreqMonthly, 1, count, opts...)
+}
+
 type Event struct {
 	header      Header
 	start       Date
 	end         Date
+	duration    Duration
+	rrule       RRule
 	stamp       Date
 	uid         UID
 	class       Class
 	createdAt   Date
 	modifiedAt  Date
 	location    Location
+	url         URL
+	categories  Categories
 	sequence    Sequence
 	status      Status
 	summary     Summary
@@ -130,6 +253,43 @@ type Event struct {
 	tailer      Tailer
 }
 
+func (e *Event) validate() error {
+	if e.url != "" {
+		u, err := url.ParseRequestURI(string(e.url))
+		if err != nil {
+			return fmt.Errorf("invalid url %q: %w", e.url, err)
+		}
+		if !u.IsAbs() {
+			return fmt.Errorf("invalid url %q: not absolute", e.url)
+		}
+	}
+	return nil
+}
+
+// Duration return event duration
+// explicit DURATION is used if DTEND not set, all-day event without end lasts one day
+func (e *Event) Duration() time.Duration {
+	switch {
+	case !e.end.IsZero():
+		return e.end.Sub(e.start.Time)
+	case e.duration != 0:
+		return time.Duration(e.duration)
+	case e.start.layout == LayoutDate:
+		return 24 * time.Hour
+	default:
+		return 0
+	}
+}
+
+// Overlaps report whether two events overlap
+// events are treated as half-open intervals [start, end), so adjacent events do not overlap
+func (e *Event) Overlaps(other Event) bool {
+	start, end := e.start.Time, e.start.Add(e.Duration())
+	otherStart, otherEnd := other.start.Time, other.start.Add(other.Duration())
+	// events starting at same time always overlap, even zero-length ones
+	return start.Before(otherEnd) && otherStart.Before(end) || start.Equal(otherStart)
+}
+
 func (e *Event) Output() []byte {
 	var buf bytes.Buffer
 
@@ -143,6 +303,13 @@ func (e *Event) Output() []byte {
 	if !e.end.IsZero() {
 		buf.Write(e.end.Output())
 		buf.WriteByte('\n')
+	} else if e.duration != 0 {
+		buf.Write(e.duration.Output())
+		buf.WriteByte('\n')
+	}
+	if e.rrule.Freq != "" {
+		buf.Write(e.rrule.Output())
+		buf.WriteByte('\n')
 	}
 	if !e.stamp.IsZero() {
 		buf.Write(e.stamp.Output())
@@ -172,6 +339,14 @@ func (e *Event) Output() []byte {
 		buf.Write(e.location.Output())
 		buf.WriteByte('\n')
 	}
+	if e.url != "" {
+		buf.Write(e.url.Output())
+		buf.WriteByte('\n')
+	}
+	if len(e.categories) > 0 {
+		buf.Write(e.categories.Output())
+		buf.WriteByte('\n')
+	}
 
 	buf.Write(e.sequence.Output())
 	buf.WriteByte('\n')
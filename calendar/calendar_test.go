@@ -1,6 +1,11 @@
 package calendar
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
 	"testing"
 	"time"
 )
@@ -50,3 +55,290 @@ func TestOutput(t *testing.T) {
 
 	t.Logf("out:\n%s", c.Output())
 }
+
+func TestEvent_Overlaps(t *testing.T) {
+	base := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	event := func(start time.Time, d time.Duration) Event { return *NewEvent("", "", start, WithEnd(start.Add(d))) }
+	allDay := func(day time.Time) Event { return *NewEvent("", "", day, SetStartFormat(LayoutDate, DateFormat)) }
+
+	for _, testcase := range []struct {
+		name     string
+		a, b     Event
+		expected bool
+	}{
+		{"adjacent", event(base, time.Hour), event(base.Add(time.Hour), time.Hour), false},
+		{"identical", event(base, time.Hour), event(base, time.Hour), true},
+		{"partial", event(base, time.Hour), event(base.Add(30*time.Minute), time.Hour), true},
+		{"contained", event(base, 3*time.Hour), event(base.Add(time.Hour), time.Hour), true},
+		{"duration", *NewEvent("", "", base, WithDuration(time.Hour)), event(base.Add(30*time.Minute), time.Hour), true},
+		{"all-day", allDay(base.Truncate(24 * time.Hour)), event(base, time.Hour), true},
+		{"all-day next day", allDay(base.Truncate(24 * time.Hour)), allDay(base.Truncate(24 * time.Hour).Add(24 * time.Hour)), false},
+	} {
+		if overlaps := testcase.a.Overlaps(testcase.b); overlaps != testcase.expected {
+			t.Errorf("%s: expect overlaps %t, got %t", testcase.name, testcase.expected, overlaps)
+		}
+		if overlaps := testcase.b.Overlaps(testcase.a); overlaps != testcase.expected {
+			t.Errorf("%s(reversed): expect overlaps %t, got %t", testcase.name, testcase.expected, overlaps)
+		}
+	}
+}
+
+func TestCalendar_FindConflicts(t *testing.T) {
+	base := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	c := NewCalendar("test calendar", "")
+	c.AddEvents(
+		*NewEvent("A", "", base, WithEnd(base.Add(time.Hour))),
+		*NewEvent("B", "", base.Add(time.Hour), WithDuration(time.Hour)),
+		*NewEvent("C", "", base.Add(90*time.Minute), WithEnd(base.Add(3*time.Hour))),
+	)
+
+	conflicts := c.FindConflicts()
+	if len(conflicts) != 1 || conflicts[0][0].summary != "B" || conflicts[0][1].summary != "C" {
+		t.Errorf("expect conflict between B and C, got: %+v", conflicts)
+	}
+	if d := conflicts[0][0].Duration(); d != time.Hour {
+		t.Errorf("expect duration 1h, got: %s", d)
+	}
+}
+
+func TestDuration_Output(t *testing.T) {
+	for d, expected := range map[time.Duration]string{
+		0:                          "DURATION:PT0S",
+		90 * time.Minute:           "DURATION:PT1H30M",
+		26*time.Hour + time.Second: "DURATION:P1DT2H1S",
+		48 * time.Hour:             "DURATION:P2D",
+		-15 * time.Minute:          "DURATION:-PT15M",
+	} {
+		if out := string(Duration(d).Output()); out != expected {
+			t.Errorf("%s: expect %s, got %s", d, expected, out)
+		}
+	}
+}
+
+func TestCalendar_MergeWith(t *testing.T) {
+	now := time.Now()
+	a := NewCalendar("A", "", WithProdID("prod-a"))
+	a.AddEvents(
+		*NewEvent("old", "", now, WithUID("1"), WithModifiedAt(now.Add(-time.Hour))),
+		*NewEvent("only a", "", now, WithUID("2"), WithModifiedAt(now)),
+	)
+	b := NewCalendar("B", "", WithProdID("prod-b"))
+	b.AddEvents(
+		*NewEvent("new", "", now, WithUID("1"), WithModifiedAt(now)),
+		*NewEvent("stale", "", now, WithUID("2"), WithModifiedAt(now.Add(-time.Hour))),
+		*NewEvent("only b", "", now, WithUID("3")),
+	)
+
+	merged := a.MergeWith(b)
+	if merged.prodID != "prod-a" || merged.version != a.version || merged.scale != a.scale {
+		t.Errorf("merged calendar should inherit properties of receiver, got: %s %s %s", merged.prodID, merged.version, merged.scale)
+	}
+
+	uids := make(map[UID]bool)
+	for _, event := range merged.events {
+		if uids[event.uid] {
+			t.Errorf("duplicate uid %s in merged calendar", event.uid)
+		}
+		uids[event.uid] = true
+	}
+	for uid, summary := range map[string]Summary{"1": "new", "2": "only a", "3": "only b"} {
+		if event, ok := merged.EventByUID(uid); !ok || event.summary != summary {
+			t.Errorf("uid %s: expect event %q, got: %+v", uid, summary, event)
+		}
+	}
+	if len(a.events) != 2 || a.events[0].summary != "old" {
+		t.Errorf("merge should not modify receiver")
+	}
+	if _, ok := merged.EventByUID("4"); ok {
+		t.Errorf("unexpected event for unknown uid")
+	}
+}
+
+func TestCategories_Output(t *testing.T) {
+	event := NewEvent("meeting", "", time.Now(), WithCategories("work", "important"))
+	if !strings.Contains(string(event.Output()), "\nCATEGORIES:work,important\n") {
+		t.Errorf("expect categories in event output, got:\n%s", event.Output())
+	}
+
+	if out := string(Categories{`a,b`, `c;d`, `e\f`}.Output()); out != `CATEGORIES:a\,b,c\;d,e\\f` {
+		t.Errorf("unexpected escaped categories: %s", out)
+	}
+
+	if strings.Contains(string(NewEvent("meeting", "", time.Now()).Output()), "CATEGORIES") {
+		t.Errorf("empty categories should not be output")
+	}
+}
+
+func TestNewRecurringEvent(t *testing.T) {
+	start := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	output := string(NewDailyEvent("standup", "", start, 10, WithDuration(15*time.Minute)).Output())
+	if !strings.Contains(output, "\nDTSTART:20240101T090000Z\nDURATION:PT15M\nRRULE:FREQ=DAILY;COUNT=10\n") {
+		t.Errorf("expect daily rrule after start and duration, got:\n%s", output)
+	}
+
+	for _, testcase := range []struct {
+		event    *Event
+		expected string
+	}{
+		{NewWeeklyEvent("review", "", start, 0), "\nRRULE:FREQ=WEEKLY\n"},
+		{NewMonthlyEvent("report", "", start, 12), "\nRRULE:FREQ=MONTHLY;COUNT=12\n"},
+		{NewRecurringEvent("sync", "", start, FreqDaily, 2, 5), "\nRRULE:FREQ=DAILY;INTERVAL=2;COUNT=5\n"},
+	} {
+		if output := string(testcase.event.Output()); !strings.Contains(output, testcase.expected) {
+			t.Errorf("expect %q in output, got:\n%s", testcase.expected, output)
+		}
+	}
+
+	if strings.Contains(string(NewEvent("once", "", start).Output()), "RRULE") {
+		t.Errorf("single event should not output rrule")
+	}
+}
+
+func TestWithURL(t *testing.T) {
+	start := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+
+	event, err := NewEventWithValidation("meeting", "", start, WithLocation("room"), WithURL("https://example.com/meeting"))
+	if err != nil {
+		t.Fatalf("build event with absolute url fail: %s", err)
+	}
+	if output := string(event.Output()); !strings.Contains(output, "\nLOCATION:room\nURL:https://example.com/meeting\n") {
+		t.Errorf("expect url after location, got:\n%s", output)
+	}
+	if strings.Contains(string(NewEvent("meeting", "", start).Output()), "URL:") {
+		t.Errorf("event without url should not output url")
+	}
+
+	for _, u := range []string{"/meeting", "example.com/meeting", "meeting"} {
+		if _, err := NewEventWithValidation("meeting", "", start, WithURL(u)); err == nil {
+			t.Errorf("expect error for relative url %q", u)
+		}
+		if output := string(NewEvent("meeting", "", start, WithURL(u)).Output()); !strings.Contains(output, "\nURL:"+u+"\n") {
+			t.Errorf("NewEvent should keep url %q, got:\n%s", u, output)
+		}
+	}
+}
+
+func TestText_Output(t *testing.T) {
+	for _, testcase := range []struct {
+		output   []byte
+		expected string
+	}{
+		{Summary("Meeting: Q4 Review").Output(), `SUMMARY:Meeting: Q4 Review`},
+		{Desc("agenda; budget, hiring\nnotes: C:\\docs").Output(), `DESCRIPTION:agenda\; budget\, hiring\nnotes: C:\\docs`},
+		{Location("Room 1, Floor 2").Output(), `LOCATION:Room 1\, Floor 2`},
+		{CalName("Team: Work;Life").Output(), `X-WR-CALNAME:Team: Work\;Life`},
+		{CalDesc("line1\r\nline2").Output(), `X-WR-CALDESC:line1\nline2`},
+	} {
+		if string(testcase.output) != testcase.expected {
+			t.Errorf("expect %s, got %s", testcase.expected, testcase.output)
+		}
+	}
+
+	// escaped value parsed back by splitting name at first colon and unescaping
+	unescape := strings.NewReplacer(`\\`, `\`, `\;`, ";", `\,`, ",", `\n`, "\n", `\N`, "\n").Replace
+	text := "Meeting: Q4 Review; part 1, \\draft\\\nsee notes"
+	name, value, _ := strings.Cut(string(Summary(text).Output()), ":")
+	if name != "SUMMARY" || unescape(value) != text {
+		t.Errorf("expect %q parsed back, got %s: %q", text, name, unescape(value))
+	}
+}
+
+func TestNewAllDayEvent(t *testing.T) {
+	shanghai := time.FixedZone("CST", 8*3600)
+	event := NewAllDayEvent("holiday", "new year", time.Date(2025, 1, 1, 0, 0, 0, 0, shanghai),
+		WithAllDayEnd(time.Date(2025, 1, 3, 23, 0, 0, 0, shanghai)), WithUID("holiday@example.com"))
+
+	output := string(event.Output())
+	for _, line := range []string{"\nDTSTART;VALUE=DATE:20250101\n", "\nDTEND;VALUE=DATE:20250103\n"} {
+		if !strings.Contains(output, line) {
+			t.Errorf("expect %q in output, got:\n%s", line, output)
+		}
+	}
+	if d := event.Duration(); d != 48*time.Hour {
+		t.Errorf("expect 2 days, got %s", d)
+	}
+	if output := string(NewAllDayEvent("holiday", "", time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)).Output()); strings.Contains(output, "DTEND") || strings.Contains(output, "DTSTART;VALUE=DATE:20250101T") {
+		t.Errorf("unexpected all-day event without end:\n%s", output)
+	}
+
+	// every content line of calendar is a property with name, optional params and value, as importers expect
+	c := NewCalendar("holidays", "")
+	c.AddEvents(*event)
+	lines := strings.Split(strings.TrimSuffix(string(c.Output()), "\n"), "\n")
+	if lines[0] != "BEGIN:VCALENDAR" || lines[len(lines)-1] != "END:VCALENDAR" {
+		t.Errorf("unexpected calendar:\n%s", c.Output())
+	}
+	for _, line := range lines {
+		name, value, ok := strings.Cut(line, ":")
+		if !ok || name == "" || strings.ContainsAny(name, " ") || (strings.HasPrefix(name, "DT") && strings.Contains(name, "VALUE=DATE") && len(value) != len(LayoutDate)) {
+			t.Errorf("invalid content line: %q", line)
+		}
+	}
+}
+
+func newBenchCalendar(count int) *Calendar {
+	c := NewCalendar("bench calendar", "calendar for benchmark", WithTimeZone(TZShanghai))
+	start := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	for i := 0; i < count; i++ {
+		c.AddEvents(*NewEvent(fmt.Sprintf("event %d", i), "benchmark event", start.Add(time.Duration(i)*time.Hour)))
+	}
+	return c
+}
+
+func TestCalendar_WriteTo(t *testing.T) {
+	c := newBenchCalendar(3)
+
+	var buf bytes.Buffer
+	n, err := c.WriteTo(&buf)
+	if err != nil {
+		t.Fatalf("write calendar fail: %s", err)
+	}
+	if n != int64(buf.Len()) {
+		t.Errorf("expect %d bytes written, got %d", buf.Len(), n)
+	}
+	if !bytes.Equal(buf.Bytes(), c.Output()) {
+		t.Errorf("expect same output as Output, got:\n%s", buf.Bytes())
+	}
+}
+
+type failWriter struct{ limit int }
+
+func (w *failWriter) Write(p []byte) (int, error) {
+	if len(p) > w.limit {
+		n := w.limit
+		w.limit = 0
+		return n, errors.New("write fail")
+	}
+	w.limit -= len(p)
+	return len(p), nil
+}
+
+func TestCalendar_WriteTo_error(t *testing.T) {
+	c := newBenchCalendar(3)
+
+	n, err := c.WriteTo(&failWriter{limit: 100})
+	if err == nil {
+		t.Fatal("expect write error, got nil")
+	}
+	if n != 100 {
+		t.Errorf("expect 100 bytes written, got %d", n)
+	}
+}
+
+func BenchmarkCalendar_Output(b *testing.B) {
+	c := newBenchCalendar(10000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = io.Discard.Write(c.Output())
+	}
+}
+
+func BenchmarkCalendar_WriteTo(b *testing.B) {
+	c := newBenchCalendar(10000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = c.WriteTo(io.Discard)
+	}
+}
@@ -3,6 +3,7 @@ package calendar
 import (
 	"bytes"
 	"fmt"
+	"strings"
 	"time"
 )
 
@@ -27,14 +28,17 @@ type (
 	CalDesc  string // 日历描述
 
 	// ============== VEVENT ==============
-	Status      string // 状态 TENTATIVE 试探 CONFIRMED 确认 CANCELLED 取消
-	Summary     string // 简介 一般是标题
-	UID         string // UID
-	Class       string // 事件类型
-	Transparent string // 对于忙闲查询是否透明 OPAQUE 不透明 TRANSPARENT 透明
-	Location    string // location
-	Sequence    int    // 排列序号 0 最高
-	Desc        string // 描述
+	Status      string        // 状态 TENTATIVE 试探 CONFIRMED 确认 CANCELLED 取消
+	Summary     string        // 简介 一般是标题
+	UID         string        // UID
+	Class       string        // 事件类型
+	Transparent string        // 对于忙闲查询是否透明 OPAQUE 不透明 TRANSPARENT 透明
+	Location    string        // location
+	URL         string        // 相关链接
+	Sequence    int           // 排列序号 0 最高
+	Desc        string        // 描述
+	Duration    time.Duration // 持续时间 与 DTEND 不可同时使用
+	Categories  []string      // 分类
 )
 
 const (
@@ -69,19 +73,96 @@ func (h Header) Output() []byte      { return append([]byte("BEGIN:"), []byte(h)
 func (t Tailer) Output() []byte      { return append([]byte("END:"), []byte(t)...) }
 func (id ProdID) Output() []byte     { return append([]byte("PRODID:"), []byte(id)...) }
 func (v Version) Output() []byte     { return append([]byte("VERSION:"), []byte(v)...) }
-func (n CalName) Output() []byte     { return append([]byte("X-WR-CALNAME:"), []byte(n)...) }
-func (d CalDesc) Output() []byte     { return append([]byte("X-WR-CALDESC:"), []byte(d)...) }
+func (n CalName) Output() []byte     { return append([]byte("X-WR-CALNAME:"), escapeText(string(n))...) }
+func (d CalDesc) Output() []byte     { return append([]byte("X-WR-CALDESC:"), escapeText(string(d))...) }
 func (s Scale) Output() []byte       { return append([]byte("CALSCALE:"), []byte(s)...) }
 func (m Method) Output() []byte      { return append([]byte("METHOD:"), []byte(m)...) }
 func (tz TimeZone) Output() []byte   { return append([]byte("X-WR-TIMEZONE:"), []byte(tz)...) }
 func (s Status) Output() []byte      { return append([]byte("STATUS:"), []byte(s)...) }
-func (s Summary) Output() []byte     { return append([]byte("SUMMARY:"), []byte(s)...) }
+func (s Summary) Output() []byte     { return append([]byte("SUMMARY:"), escapeText(string(s))...) }
 func (u UID) Output() []byte         { return append([]byte("UID:"), []byte(u)...) }
 func (c Class) Output() []byte       { return append([]byte("CLASS:"), []byte(c)...) }
 func (t Transparent) Output() []byte { return append([]byte("TRANSP:"), []byte(t)...) }
-func (l Location) Output() []byte    { return append([]byte("LOCATION:"), []byte(l)...) }
+func (l Location) Output() []byte    { return append([]byte("LOCATION:"), escapeText(string(l))...) }
+func (u URL) Output() []byte         { return append([]byte("URL:"), []byte(u)...) }
 func (s Sequence) Output() []byte    { return append([]byte("SEQUENCE:"), []byte(fmt.Sprint(s))...) }
-func (d Desc) Output() []byte        { return append([]byte("DESCRIPTION:"), []byte(d)...) }
+func (d Desc) Output() []byte        { return append([]byte("DESCRIPTION:"), escapeText(string(d))...) }
+
+// Output output categories like CATEGORIES:work,important, with special characters escaped
+func (c Categories) Output() []byte {
+	var buf bytes.Buffer
+	buf.WriteString("CATEGORIES:")
+	for i, category := range c {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		buf.WriteString(escapeText(category))
+	}
+	return buf.Bytes()
+}
+
+// escapeText escape TEXT value as RFC 5545 3.3.11, colon needs no escaping
+var escapeText = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace
+
+// Output output duration like DURATION:P1DT2H30M
+func (d Duration) Output() []byte {
+	var buf bytes.Buffer
+	buf.WriteString("DURATION:")
+
+	dur := time.Duration(d)
+	if dur < 0 {
+		buf.WriteByte('-')
+		dur = -dur
+	}
+	buf.WriteByte('P')
+	if days := dur / (24 * time.Hour); days > 0 {
+		fmt.Fprintf(&buf, "%dD", days)
+		dur -= days * 24 * time.Hour
+	}
+	if dur > 0 || buf.Len() == len("DURATION:P") {
+		buf.WriteByte('T')
+		if h := dur / time.Hour; h > 0 {
+			fmt.Fprintf(&buf, "%dH", h)
+		}
+		if m := dur % time.Hour / time.Minute; m > 0 {
+			fmt.Fprintf(&buf, "%dM", m)
+		}
+		if s := dur % time.Minute / time.Second; s > 0 || dur < time.Minute {
+			fmt.Fprintf(&buf, "%dS", s)
+		}
+	}
+	return buf.Bytes()
+}
+
+// RRuleFreq recurrence frequency
+type RRuleFreq string
+
+const (
+	FreqDaily   RRuleFreq = "DAILY"
+	FreqWeekly  RRuleFreq = "WEEKLY"
+	FreqMonthly RRuleFreq = "MONTHLY"
+	FreqYearly  RRuleFreq = "YEARLY"
+)
+
+// RRule 重复规则
+type RRule struct {
+	Freq     RRuleFreq
+	Interval int // repeat every Interval Freq, 1 if not positive
+	Count    int // total occurrences, unlimited if not positive
+}
+
+// Output output rrule like RRULE:FREQ=DAILY;INTERVAL=2;COUNT=10
+func (r RRule) Output() []byte {
+	var buf bytes.Buffer
+	buf.WriteString("RRULE:FREQ=" + string(r.Freq))
+	if r.Interval > 1 {
+		fmt.Fprintf(&buf, ";INTERVAL=%d", r.Interval)
+	}
+	if r.Count > 0 {
+		fmt.Fprintf(&buf, ";COUNT=%d", r.Count)
+	}
+	return buf.Bytes()
+}
 
 // ============== Date ==============
 
@@ -61,6 +61,14 @@ var (
 			return e
 		}
 	}
+	// WithAllDayEnd set end date of all-day event like DTEND;VALUE=DATE:20250102, end date is exclusive
+	WithAllDayEnd = func(date time.Time) EventOption {
+		return func(e *Event) *Event {
+			e.end = NewDate("DTEND", allDay(date))
+			setTimeFormat(&e.end, LayoutDate, DateFormat)
+			return e
+		}
+	}
 	// SetEndFormat set date format
 	SetEndFormat = func(layout string, configs ...string) EventOption {
 		return func(e *Event) *Event {
@@ -68,6 +76,27 @@ var (
 			return e
 		}
 	}
+	// WithDuration set duration, used when end time not set
+	WithDuration = func(duration time.Duration) EventOption {
+		return func(e *Event) *Event {
+			e.duration = Duration(duration)
+			return e
+		}
+	}
+	// WithCategories set categories
+	WithCategories = func(categories ...string) EventOption {
+		return func(e *Event) *Event {
+			e.categories = Categories(categories)
+			return e
+		}
+	}
+	// WithRRule set recurrence rule
+	WithRRule = func(rrule RRule) EventOption {
+		return func(e *Event) *Event {
+			e.rrule = rrule
+			return e
+		}
+	}
 	// WithStamp set stamp
 	WithStamp = func(stamp time.Time) EventOption {
 		return func(e *Event) *Event {
@@ -131,6 +160,13 @@ var (
 			return e
 		}
 	}
+	// WithURL set url linking to more information, validated only by NewEventWithValidation
+	WithURL = func(u string) EventOption {
+		return func(e *Event) *Event {
+			e.url = URL(u)
+			return e
+		}
+	}
 	// WithSequence set location
 	WithSequence = func(sequence int) EventOption {
 		return func(e *Event) *Event {
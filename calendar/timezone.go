@@ -0,0 +1,107 @@
+package calendar
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+)
+
+const layoutLocalTime = "20060102T150405"
+
+// tzTransition timezone offset transition
+type tzTransition struct {
+	at         time.Time // transition instant
+	name       string    // zone name after transition
+	offsetFrom int       // offset in seconds before transition
+	offsetTo   int       // offset in seconds after transition
+}
+
+// generateVTIMEZONE generate minimal VTIMEZONE component for timezone
+// DST transition rules are derived from transitions of current year, return nil if timezone unknown
+func generateVTIMEZONE(tz TimeZone) []byte {
+	loc, err := time.LoadLocation(string(tz))
+	if err != nil {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("BEGIN:VTIMEZONE\n")
+	buf.WriteString("TZID:" + string(tz) + "\n")
+	buf.WriteString("X-LIC-LOCATION:" + string(tz) + "\n")
+
+	transitions := findTransitions(loc, time.Now().Year())
+	if len(transitions) == 0 { // no DST
+		name, offset := time.Date(time.Now().Year(), 1, 1, 0, 0, 0, 0, loc).Zone()
+		writeObservance(&buf, "STANDARD", name, offset, offset, "19700101T000000", "")
+	}
+	for _, tr := range transitions {
+		component := "STANDARD"
+		if tr.offsetTo > tr.offsetFrom {
+			component = "DAYLIGHT"
+		}
+		// DTSTART of observance is local time before transition
+		start := tr.at.In(time.FixedZone("", tr.offsetFrom))
+		writeObservance(&buf, component, tr.name, tr.offsetFrom, tr.offsetTo, start.Format(layoutLocalTime), yearlyRule(start))
+	}
+
+	buf.WriteString("END:VTIMEZONE")
+	return buf.Bytes()
+}
+
+func writeObservance(buf *bytes.Buffer, component, name string, offsetFrom, offsetTo int, start, rrule string) {
+	buf.WriteString("BEGIN:" + component + "\n")
+	buf.WriteString("TZOFFSETFROM:" + formatOffset(offsetFrom) + "\n")
+	buf.WriteString("TZOFFSETTO:" + formatOffset(offsetTo) + "\n")
+	buf.WriteString("TZNAME:" + name + "\n")
+	buf.WriteString("DTSTART:" + start + "\n")
+	if rrule != "" {
+		buf.WriteString("RRULE:" + rrule + "\n")
+	}
+	buf.WriteString("END:" + component + "\n")
+}
+
+// findTransitions find offset transitions of loc in year
+func findTransitions(loc *time.Location, year int) (transitions []tzTransition) {
+	start := time.Date(year, 1, 1, 0, 0, 0, 0, loc)
+	end := start.AddDate(1, 0, 0)
+	for t := start; t.Before(end); t = t.Add(24 * time.Hour) {
+		next := t.Add(24 * time.Hour)
+		_, offsetFrom := t.Zone()
+		_, offsetTo := next.Zone()
+		if offsetFrom == offsetTo {
+			continue
+		}
+
+		// binary search transition instant in (lo, hi]
+		lo, hi := t, next
+		for hi.Sub(lo) > time.Second {
+			mid := lo.Add(hi.Sub(lo) / 2)
+			if _, offset := mid.Zone(); offset == offsetFrom {
+				lo = mid
+			} else {
+				hi = mid
+			}
+		}
+		name, _ := hi.Zone()
+		transitions = append(transitions, tzTransition{at: hi, name: name, offsetFrom: offsetFrom, offsetTo: offsetTo})
+	}
+	return transitions
+}
+
+// yearlyRule return RRULE repeats on same weekday of month every year, e.g. FREQ=YEARLY;BYMONTH=3;BYDAY=2SU
+func yearlyRule(t time.Time) string {
+	weekday := [...]string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}[t.Weekday()]
+	if t.AddDate(0, 0, 7).Month() != t.Month() { // last week of month
+		return fmt.Sprintf("FREQ=YEARLY;BYMONTH=%d;BYDAY=-1%s", t.Month(), weekday)
+	}
+	return fmt.Sprintf("FREQ=YEARLY;BYMONTH=%d;BYDAY=%d%s", t.Month(), (t.Day()-1)/7+1, weekday)
+}
+
+// formatOffset format offset in seconds like +0800
+func formatOffset(offset int) string {
+	sign := '+'
+	if offset < 0 {
+		sign, offset = '-', -offset
+	}
+	return fmt.Sprintf("%c%02d%02d", sign, offset/3600, offset%3600/60)
+}
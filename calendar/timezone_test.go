@@ -0,0 +1,37 @@
+package calendar
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerateVTIMEZONE(t *testing.T) {
+	out := string(NewCalendar("test calendar", "", WithTimeZone(TZShanghai)).Output())
+	for _, line := range []string{
+		"BEGIN:VTIMEZONE\nTZID:Asia/Shanghai\n",
+		"BEGIN:STANDARD\nTZOFFSETFROM:+0800\nTZOFFSETTO:+0800\nTZNAME:CST\nDTSTART:19700101T000000\nEND:STANDARD\n",
+		"END:VTIMEZONE\n",
+	} {
+		if !strings.Contains(out, line) {
+			t.Errorf("calendar output should contain %q, got:\n%s", line, out)
+		}
+	}
+}
+
+func TestGenerateVTIMEZONE_dst(t *testing.T) {
+	out := string(generateVTIMEZONE("America/New_York"))
+	for _, line := range []string{
+		"BEGIN:DAYLIGHT\nTZOFFSETFROM:-0500\nTZOFFSETTO:-0400\nTZNAME:EDT\n",
+		"RRULE:FREQ=YEARLY;BYMONTH=3;BYDAY=2SU\n",
+		"BEGIN:STANDARD\nTZOFFSETFROM:-0400\nTZOFFSETTO:-0500\nTZNAME:EST\n",
+		"RRULE:FREQ=YEARLY;BYMONTH=11;BYDAY=1SU\n",
+	} {
+		if !strings.Contains(out, line) {
+			t.Errorf("vtimezone should contain %q, got:\n%s", line, out)
+		}
+	}
+
+	if out := generateVTIMEZONE("Unknown/Zone"); out != nil {
+		t.Errorf("unknown timezone should generate nothing, got: %s", out)
+	}
+}
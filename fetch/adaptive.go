@@ -0,0 +1,91 @@
+package fetch
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// NewAdaptiveTimeoutMiddleware return middleware cancels request after max(baseTimeout, latency*1.5),
+// latency is targetPercentile (e.g. 0.99) of last windowSize request latencies, requests timed out are not counted
+// so timeout does not escalate while service hangs
+// timeout covers all middlewares inside it, so put it outside retry middleware to limit every attempt
+// it cancels request through context set by DoRequestWithOptions, called elsewhere (e.g. by ChainMiddleware directly)
+// it returns error without sending request
+func NewAdaptiveTimeoutMiddleware(baseTimeout time.Duration, targetPercentile float64, windowSize int) Middleware {
+	return newAdaptiveTimeout(baseTimeout, targetPercentile, windowSize).middleware
+}
+
+func newAdaptiveTimeout(baseTimeout time.Duration, targetPercentile float64, windowSize int) *adaptiveTimeout {
+	if windowSize <= 0 {
+		windowSize = 1
+	}
+	return &adaptiveTimeout{base: baseTimeout, percentile: targetPercentile, window: make([]time.Duration, 0, windowSize)}
+}
+
+// adaptiveTimeout timeout adapting to recent latencies
+type adaptiveTimeout struct {
+	base       time.Duration
+	percentile float64
+
+	mu     sync.Mutex
+	window []time.Duration // ring buffer of latencies
+	next   int             // index to overwrite when window is full
+}
+
+func (a *adaptiveTimeout) middleware(ctx RequestContext, next func() (int, []byte, http.Header, error)) (int, []byte, http.Header, error) {
+	if ctx.cancel == nil {
+		return -1, nil, nil, errors.New("adaptive timeout middleware requires request sent by DoRequestWithOptions")
+	}
+	timeout := a.timeout()
+
+	timer := time.AfterFunc(timeout, ctx.cancel)
+	start := time.Now()
+	statusCode, content, headers, err := next()
+	latency := time.Since(start)
+
+	if !timer.Stop() { // fired
+		return -1, nil, nil, fmt.Errorf("adaptive timeout %s exceeded: %w", timeout, err)
+	}
+	a.record(latency)
+	return statusCode, content, headers, err
+}
+
+// timeout return timeout of next request
+func (a *adaptiveTimeout) timeout() time.Duration {
+	a.mu.Lock()
+	if len(a.window) == 0 {
+		a.mu.Unlock()
+		return a.base
+	}
+	latencies := append([]time.Duration(nil), a.window...)
+	a.mu.Unlock()
+
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	i := int(math.Ceil(a.percentile*float64(len(latencies)))) - 1
+	if i < 0 {
+		i = 0
+	} else if i >= len(latencies) {
+		i = len(latencies) - 1
+	}
+
+	if timeout := latencies[i] * 3 / 2; timeout > a.base {
+		return timeout
+	}
+	return a.base
+}
+
+func (a *adaptiveTimeout) record(latency time.Duration) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if len(a.window) < cap(a.window) {
+		a.window = append(a.window, latency)
+		return
+	}
+	a.window[a.next] = latency
+	a.next = (a.next + 1) % len(a.window)
+}
@@ -0,0 +1,75 @@
+package fetch
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestAdaptiveTimeout(t *testing.T) {
+	a := newAdaptiveTimeout(50*time.Millisecond, 0.99, 10)
+	if timeout := a.timeout(); timeout != 50*time.Millisecond {
+		t.Errorf("expect base timeout without latency, got %s", timeout)
+	}
+
+	// service slows down, timeout grows to p99 * 1.5
+	for i := 1; i <= 10; i++ {
+		a.record(time.Duration(i) * 20 * time.Millisecond)
+	}
+	if timeout := a.timeout(); timeout != 300*time.Millisecond {
+		t.Errorf("expect timeout grows to 300ms, got %s", timeout)
+	}
+
+	// service recovers, slow latencies roll out of window
+	for i := 0; i < 10; i++ {
+		a.record(10 * time.Millisecond)
+	}
+	if timeout := a.timeout(); timeout != 50*time.Millisecond {
+		t.Errorf("expect timeout shrinks to base, got %s", timeout)
+	}
+}
+
+func TestNewAdaptiveTimeoutMiddleware(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		delay, _ := strconv.Atoi(r.URL.Query().Get("delay"))
+		select {
+		case <-time.After(time.Duration(delay) * time.Millisecond):
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+
+	mw := WithMiddleware(NewAdaptiveTimeoutMiddleware(200*time.Millisecond, 0.99, 5))
+	get := func(delay int) error {
+		_, _, _, err := DoRequestWithOptions(http.MethodGet, server.URL+"?delay="+strconv.Itoa(delay), []RequestOption{mw}, nil)
+		return err
+	}
+
+	if err := get(225); !errors.Is(err, context.Canceled) {
+		t.Errorf("expect request canceled by base timeout, got: %v", err)
+	}
+	// latencies of 170ms raise timeout above 255ms
+	for i := 0; i < 5; i++ {
+		if err := get(170); err != nil {
+			t.Fatalf("request fail: %s", err)
+		}
+	}
+	if err := get(225); err != nil {
+		t.Errorf("request within adapted timeout should succeed, got: %v", err)
+	}
+}
+
+func TestNewAdaptiveTimeoutMiddleware_noCancel(t *testing.T) {
+	var sent bool
+	_, _, _, err := ChainMiddleware(NewAdaptiveTimeoutMiddleware(time.Second, 0.99, 5))(RequestContext{}, func() (int, []byte, http.Header, error) {
+		sent = true
+		return http.StatusOK, nil, nil, nil
+	})
+	if err == nil || sent {
+		t.Errorf("expect error without sending request when request can not be canceled, got sent %t, err: %v", sent, err)
+	}
+}
@@ -0,0 +1,111 @@
+package fetch
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// BatchRequest request sent by DoBatch
+type BatchRequest struct {
+	Method  string
+	URL     string
+	Body    []byte
+	Options []RequestOption
+}
+
+// BatchResult result of BatchRequest
+type BatchResult struct {
+	StatusCode int
+	Content    []byte
+	Headers    http.Header
+	Err        error
+}
+
+// BatchOptions options of DoBatch
+type BatchOptions struct {
+	Concurrency int           // max requests in flight, not positive means unlimited
+	FailFast    bool          // cancel pending requests when any request fails with non-retryable error
+	Timeout     time.Duration // timeout of whole batch, zero means no limit
+
+	RetryConfig *RetryConfig // classify failures for FailFast, DefaultRetryConfig if nil
+}
+
+// DoBatch send requests concurrently, results are in same order as reqs
+// returned error is the first non-retryable failure when FailFast set, or failures of all requests joined,
+// use WithResponseValidation to treat unexpected status code as failure,
+// failure is retryable when RetryConfig would retry it, e.g. network error or status code in RetryOnStatus
+func DoBatch(ctx context.Context, reqs []BatchRequest, opts BatchOptions) ([]BatchResult, error) {
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	retryConfig := opts.RetryConfig
+	if retryConfig == nil {
+		retryConfig = &DefaultRetryConfig
+	}
+
+	concurrency := opts.Concurrency
+	if concurrency <= 0 || concurrency > len(reqs) {
+		concurrency = len(reqs)
+	}
+	sem := make(chan struct{}, concurrency)
+
+	var (
+		results  = make([]BatchResult, len(reqs))
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+	)
+	fail := func(err error) {
+		once.Do(func() {
+			firstErr = err
+			cancel()
+		})
+	}
+
+	for i, req := range reqs {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			results[i].Err = ctx.Err()
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, req BatchRequest) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			var body io.Reader
+			if req.Body != nil {
+				body = bytes.NewReader(req.Body)
+			}
+			result := &results[i]
+			result.StatusCode, result.Content, result.Headers, result.Err = DoRequestWithOptions(req.Method, req.URL,
+				append(req.Options[:len(req.Options):len(req.Options)], WithContext(ctx)), body)
+			if result.Err != nil && opts.FailFast && !retryConfig.retryable(result.StatusCode, result.Err) {
+				fail(result.Err)
+			}
+		}(i, req)
+	}
+	wg.Wait()
+
+	if opts.FailFast && firstErr != nil {
+		return results, firstErr
+	}
+
+	errs := make([]error, 0, len(results))
+	for _, result := range results {
+		errs = append(errs, result.Err)
+	}
+	return results, errors.Join(errs...)
+}
@@ -0,0 +1,138 @@
+package fetch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newBatchServer return server echoing index in query, failing requests with index in failed with failStatus
+func newBatchServer(t *testing.T, delay time.Duration, failStatus int, failed ...int) (server *httptest.Server, maxInFlight *int32) {
+	var inFlight int32
+	maxInFlight = new(int32)
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for peak := atomic.LoadInt32(maxInFlight); n > peak && !atomic.CompareAndSwapInt32(maxInFlight, peak, n); peak = atomic.LoadInt32(maxInFlight) {
+		}
+
+		index, _ := strconv.Atoi(r.URL.Query().Get("i"))
+		for _, i := range failed {
+			if i == index {
+				w.WriteHeader(failStatus)
+				return
+			}
+		}
+		select {
+		case <-time.After(delay):
+		case <-r.Context().Done():
+			return
+		}
+		_, _ = w.Write([]byte(strconv.Itoa(index)))
+	}))
+	t.Cleanup(server.Close)
+	return server, maxInFlight
+}
+
+func newBatchRequests(url string, n int) []BatchRequest {
+	reqs := make([]BatchRequest, n)
+	for i := range reqs {
+		reqs[i] = BatchRequest{Method: http.MethodGet, URL: fmt.Sprintf("%s?i=%d", url, i), Options: []RequestOption{
+			WithResponseValidation(func(statusCode int, _ []byte, _ http.Header) error {
+				if statusCode != http.StatusOK {
+					return fmt.Errorf("unexpected status code %d", statusCode)
+				}
+				return nil
+			}),
+		}}
+	}
+	return reqs
+}
+
+func TestDoBatch(t *testing.T) {
+	server, maxInFlight := newBatchServer(t, 20*time.Millisecond, 0)
+
+	results, err := DoBatch(context.Background(), newBatchRequests(server.URL, 10), BatchOptions{Concurrency: 3})
+	if err != nil {
+		t.Fatalf("do batch fail: %s", err)
+	}
+	for i, result := range results {
+		if result.Err != nil || result.StatusCode != http.StatusOK || string(result.Content) != strconv.Itoa(i) {
+			t.Errorf("unexpected result %d: %+v", i, result)
+		}
+	}
+	if n := atomic.LoadInt32(maxInFlight); n > 3 {
+		t.Errorf("expect at most 3 requests in flight, got %d", n)
+	}
+}
+
+func TestDoBatch_failures(t *testing.T) {
+	server, _ := newBatchServer(t, 10*time.Millisecond, http.StatusInternalServerError, 2, 5)
+
+	results, err := DoBatch(context.Background(), newBatchRequests(server.URL, 8), BatchOptions{Concurrency: 2})
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Errorf("expect validation errors joined, got: %v", err)
+	}
+	for i, result := range results {
+		if failed := i == 2 || i == 5; (result.Err != nil) != failed {
+			t.Errorf("unexpected result %d: %+v", i, result)
+		}
+	}
+}
+
+func TestDoBatch_failFast(t *testing.T) {
+	server, _ := newBatchServer(t, time.Second, http.StatusBadRequest, 1)
+
+	start := time.Now()
+	results, err := DoBatch(context.Background(), newBatchRequests(server.URL, 10), BatchOptions{Concurrency: 3, FailFast: true})
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) || validationErr.StatusCode != http.StatusBadRequest {
+		t.Errorf("expect first failure returned, got: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("expect pending requests cancelled, took %s", elapsed)
+	}
+	for i, result := range results {
+		if result.Err == nil {
+			t.Errorf("expect request %d failed or cancelled, got: %+v", i, result)
+		}
+	}
+}
+
+func TestDoBatch_failFastRetryable(t *testing.T) {
+	server, _ := newBatchServer(t, 20*time.Millisecond, http.StatusServiceUnavailable, 1)
+
+	results, err := DoBatch(context.Background(), newBatchRequests(server.URL, 6), BatchOptions{Concurrency: 2, FailFast: true})
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) || validationErr.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("expect retryable failure joined, got: %v", err)
+	}
+	for i, result := range results {
+		if (result.Err != nil) != (i == 1) {
+			t.Errorf("expect only request 1 failed without cancelling others, got %d: %+v", i, result)
+		}
+	}
+
+	// classify with custom retry config
+	config := NewRetryConfig(WithRetryOnStatus())
+	_, err = DoBatch(context.Background(), newBatchRequests(server.URL, 6), BatchOptions{Concurrency: 2, FailFast: true, RetryConfig: &config})
+	if _, joined := err.(interface{ Unwrap() []error }); joined || !errors.As(err, &validationErr) {
+		t.Errorf("expect first failure returned, got: %v", err)
+	}
+}
+
+func TestDoBatch_timeout(t *testing.T) {
+	server, _ := newBatchServer(t, time.Second, 0)
+
+	_, err := DoBatch(context.Background(), newBatchRequests(server.URL, 2), BatchOptions{Timeout: 50 * time.Millisecond})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expect deadline exceeded, got: %v", err)
+	}
+}
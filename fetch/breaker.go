@@ -0,0 +1,106 @@
+package fetch
+
+import (
+	"sync"
+	"time"
+)
+
+// CircuitState circuit breaker state
+type CircuitState int
+
+const (
+	// StateClosed requests are allowed
+	StateClosed CircuitState = iota
+	// StateOpen requests are rejected
+	StateOpen
+	// StateHalfOpen one probe request is allowed
+	StateHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	default:
+		return ""
+	}
+}
+
+// NewCircuitBreaker return a circuit breaker which opens after failureThreshold consecutive failures,
+// and allows a probe request after resetTimeout
+func NewCircuitBreaker(failureThreshold int, resetTimeout time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{failureThreshold: failureThreshold, resetTimeout: resetTimeout}
+}
+
+// CircuitBreaker circuit breaker
+type CircuitBreaker struct {
+	failureThreshold int
+	resetTimeout     time.Duration
+
+	mu       sync.Mutex
+	state    CircuitState
+	failures int
+	openedAt time.Time
+}
+
+// Allow report whether request is allowed
+func (cb *CircuitBreaker) Allow() bool {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	switch cb.state {
+	case StateOpen:
+		if time.Since(cb.openedAt) < cb.resetTimeout {
+			return false
+		}
+		cb.state = StateHalfOpen
+		return true
+	case StateHalfOpen: // probe request is in flight
+		return false
+	default:
+		return true
+	}
+}
+
+// RecordResult record request result
+func (cb *CircuitBreaker) RecordResult(success bool) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if success {
+		cb.state, cb.failures = StateClosed, 0
+		return
+	}
+
+	cb.failures++
+	if cb.state == StateHalfOpen || cb.failures >= cb.failureThreshold {
+		cb.state, cb.openedAt = StateOpen, time.Now()
+	}
+}
+
+// State return current state
+func (cb *CircuitBreaker) State() CircuitState {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	return cb.state
+}
+
+// OpenedAt return time when circuit breaker opened last time
+func (cb *CircuitBreaker) OpenedAt() time.Time {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	return cb.openedAt
+}
+
+// CircuitBreakerError request rejected by open circuit breaker
+type CircuitBreakerError struct {
+	OpenedAt time.Time
+}
+
+func (e *CircuitBreakerError) Error() string {
+	return "circuit breaker is open since " + e.OpenedAt.Format(time.RFC3339)
+}
@@ -0,0 +1,72 @@
+package fetch
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWithCircuitBreaker(t *testing.T) {
+	var requests, healthy int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		if atomic.LoadInt32(&healthy) == 0 {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	cb := NewCircuitBreaker(2, 50*time.Millisecond)
+	for i := 0; i < 2; i++ {
+		if _, err := Get(server.URL, WithCircuitBreaker(cb)); err != nil {
+			t.Errorf("request %d should reach server, got error: %s", i, err)
+		}
+	}
+	if cb.State() != StateOpen {
+		t.Errorf("expect circuit breaker open, got: %s", cb.State())
+	}
+
+	// settings should survive WithContext applied after WithCircuitBreaker
+	_, _, err := DoRequestWithContext(context.Background(), http.MethodGet, server.URL, []RequestOption{WithCircuitBreaker(cb)}, nil)
+	var cbErr *CircuitBreakerError
+	if !errors.As(err, &cbErr) {
+		t.Errorf("expect circuit breaker error, got: %v", err)
+	}
+	if n := atomic.LoadInt32(&requests); n != 2 {
+		t.Errorf("expect 2 requests reach server, got: %d", n)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	atomic.StoreInt32(&healthy, 1)
+	if _, err := Get(server.URL, WithCircuitBreaker(cb)); err != nil {
+		t.Errorf("probe request fail: %s", err)
+	}
+	if cb.State() != StateClosed {
+		t.Errorf("expect circuit breaker closed after successful probe, got: %s", cb.State())
+	}
+}
+
+func TestCircuitBreaker_halfOpen(t *testing.T) {
+	cb := NewCircuitBreaker(1, 10*time.Millisecond)
+	cb.RecordResult(false)
+	if cb.Allow() {
+		t.Errorf("open circuit breaker should reject request")
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	if !cb.Allow() {
+		t.Errorf("circuit breaker should allow probe request after reset timeout")
+	}
+	if cb.Allow() {
+		t.Errorf("half-open circuit breaker should allow only one probe request")
+	}
+
+	cb.RecordResult(false)
+	if cb.State() != StateOpen {
+		t.Errorf("expect circuit breaker open after failed probe, got: %s", cb.State())
+	}
+}
@@ -0,0 +1,119 @@
+package fetch
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// NewClientFromEnv build client from environment variables:
+// HTTP_PROXY, HTTPS_PROXY, NO_PROXY, HTTP_TIMEOUT_SECONDS, INSECURE_TLS
+func NewClientFromEnv() (*http.Client, error) {
+	proxy, err := proxyFromEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	timeout := defaultTimeout
+	if value := getEnv("HTTP_TIMEOUT_SECONDS"); value != "" {
+		seconds, err := strconv.Atoi(value)
+		if err != nil || seconds < 0 {
+			return nil, fmt.Errorf("invalid HTTP_TIMEOUT_SECONDS %q", value)
+		}
+		timeout = time.Duration(seconds) * time.Second
+	}
+
+	var insecure bool
+	if value := getEnv("INSECURE_TLS"); value != "" {
+		if insecure, err = strconv.ParseBool(value); err != nil {
+			return nil, fmt.Errorf("invalid INSECURE_TLS %q", value)
+		}
+	}
+
+	return &http.Client{Timeout: timeout, Transport: newTransport(proxy, insecure)}, nil
+}
+
+// LoadDefaultClientFromEnv replace default client with client built from environment variables
+func LoadDefaultClientFromEnv() error {
+	client, err := NewClientFromEnv()
+	if err != nil {
+		return err
+	}
+	SetDefaultClient(client)
+	return nil
+}
+
+// proxyFromEnv read proxy settings from environment variables once,
+// unlike http.ProxyFromEnvironment which caches them for the whole process
+func proxyFromEnv() (func(*http.Request) (*url.URL, error), error) {
+	httpProxy, err := parseProxyURL(getEnv("HTTP_PROXY"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid HTTP_PROXY: %w", err)
+	}
+	httpsProxy, err := parseProxyURL(getEnv("HTTPS_PROXY"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid HTTPS_PROXY: %w", err)
+	}
+	noProxy := strings.Split(getEnv("NO_PROXY"), ",")
+
+	return func(req *http.Request) (*url.URL, error) {
+		if bypassProxy(req.URL.Hostname(), noProxy) {
+			return nil, nil
+		}
+		if req.URL.Scheme == "https" {
+			return httpsProxy, nil
+		}
+		return httpProxy, nil
+	}, nil
+}
+
+// getEnv get environment variable by upper case key, fallback to lower case one
+func getEnv(key string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return os.Getenv(strings.ToLower(key))
+}
+
+func parseProxyURL(proxy string) (*url.URL, error) {
+	if proxy == "" {
+		return nil, nil
+	}
+	if !strings.Contains(proxy, "://") {
+		proxy = "http://" + proxy
+	}
+
+	u, err := url.Parse(proxy)
+	if err != nil {
+		return nil, err
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("missing host in proxy url %q", proxy)
+	}
+	return u, nil
+}
+
+func bypassProxy(host string, noProxy []string) bool {
+	if host == "localhost" {
+		return true
+	}
+	if ip := net.ParseIP(host); ip != nil && ip.IsLoopback() {
+		return true
+	}
+
+	for _, entry := range noProxy {
+		entry = strings.TrimPrefix(strings.TrimSpace(entry), ".")
+		switch {
+		case entry == "":
+			continue
+		case entry == "*", host == entry, strings.HasSuffix(host, "."+entry):
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,65 @@
+package fetch
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestNewClientFromEnv(t *testing.T) {
+	t.Setenv("HTTP_PROXY", "http://proxy.local:8080")
+	t.Setenv("HTTPS_PROXY", "secure-proxy.local:8443")
+	t.Setenv("NO_PROXY", "internal.com, .corp.net")
+	t.Setenv("HTTP_TIMEOUT_SECONDS", "5")
+	t.Setenv("INSECURE_TLS", "true")
+
+	client, err := NewClientFromEnv()
+	if err != nil {
+		t.Fatalf("new client from env fail: %s", err)
+	}
+	if client.Timeout != 5*time.Second {
+		t.Errorf("expect timeout 5s, got: %s", client.Timeout)
+	}
+
+	transport := client.Transport.(*http.Transport)
+	if !transport.TLSClientConfig.InsecureSkipVerify {
+		t.Errorf("expect insecure tls")
+	}
+
+	for target, expected := range map[string]string{
+		"http://example.com":       "http://proxy.local:8080",
+		"https://example.com":      "http://secure-proxy.local:8443",
+		"http://internal.com/path": "",
+		"https://api.corp.net":     "",
+		"http://127.0.0.1:8080":    "",
+	} {
+		req, _ := http.NewRequest(http.MethodGet, target, nil)
+		proxy, err := transport.Proxy(req)
+		if err != nil {
+			t.Errorf("get proxy for %s fail: %s", target, err)
+			continue
+		}
+		var got string
+		if proxy != nil {
+			got = proxy.String()
+		}
+		if got != expected {
+			t.Errorf("unexpected proxy for %s: %v, expect: %q", target, proxy, expected)
+		}
+	}
+}
+
+func TestNewClientFromEnv_malformed(t *testing.T) {
+	for key, value := range map[string]string{
+		"HTTP_TIMEOUT_SECONDS": "ten",
+		"INSECURE_TLS":         "maybe",
+		"HTTPS_PROXY":          "http://%zz",
+	} {
+		t.Run(key, func(t *testing.T) {
+			t.Setenv(key, value)
+			if _, err := NewClientFromEnv(); err == nil {
+				t.Errorf("expect error for %s=%s", key, value)
+			}
+		})
+	}
+}
@@ -3,9 +3,11 @@ package fetch
 import (
 	"context"
 	"crypto/tls"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"sync"
 	"time"
 )
@@ -13,17 +15,31 @@ import (
 var (
 	mu         sync.RWMutex
 	httpClient = &http.Client{
-		Timeout: 60 * time.Second,
-		Transport: &http.Transport{
-			MaxIdleConns:        5,
-			MaxIdleConnsPerHost: 5,
-			MaxConnsPerHost:     10,
-			Proxy:               http.ProxyFromEnvironment,
-			TLSClientConfig:     &tls.Config{InsecureSkipVerify: true},
-		},
+		Timeout:   defaultTimeout,
+		Transport: newTransport(http.ProxyFromEnvironment, false),
 	}
+
+	defaultOpts []RequestOption
 )
 
+const defaultTimeout = 60 * time.Second
+
+func newTransport(proxy func(*http.Request) (*url.URL, error), insecure bool) *http.Transport {
+	return &http.Transport{
+		MaxIdleConns:        5,
+		MaxIdleConnsPerHost: 5,
+		MaxConnsPerHost:     10,
+		Proxy:               proxy,
+		TLSClientConfig:     &tls.Config{InsecureSkipVerify: insecure},
+	}
+}
+
+// NewInsecureClient return client skipping TLS certificate verification, for tests or servers with self-signed certificate,
+// zero timeout means no timeout
+func NewInsecureClient(timeout time.Duration) *http.Client {
+	return &http.Client{Timeout: timeout, Transport: newTransport(http.ProxyFromEnvironment, true)}
+}
+
 // DefaultClient return default client
 func DefaultClient() *http.Client {
 	mu.RLock()
@@ -38,6 +54,31 @@ func SetDefaultClient(client *http.Client) {
 	httpClient = client
 }
 
+// RegisterDefaultOptions register options applied to all requests before per-call options,
+// so per-call options like WithSetHeader can override them
+func RegisterDefaultOptions(opts ...RequestOption) {
+	mu.Lock()
+	defer mu.Unlock()
+	defaultOpts = append(defaultOpts[:len(defaultOpts):len(defaultOpts)], opts...)
+}
+
+// UnregisterDefaultOptions remove all registered default options
+func UnregisterDefaultOptions() {
+	mu.Lock()
+	defer mu.Unlock()
+	defaultOpts = nil
+}
+
+// withDefaultOptions prepend registered default options to opts
+func withDefaultOptions(opts []RequestOption) []RequestOption {
+	mu.RLock()
+	defer mu.RUnlock()
+	if len(defaultOpts) == 0 {
+		return opts
+	}
+	return append(defaultOpts[:len(defaultOpts):len(defaultOpts)], opts...)
+}
+
 // Get ...
 func Get(url string, opts ...RequestOption) ([]byte, error) {
 	_, content, _, err := DoRequestWithOptions(http.MethodGet, url, opts, nil)
@@ -88,16 +129,74 @@ func DoRequestWithContext(ctx context.Context, method string, url string, opts [
 
 // DoRequestWithOptions 进行HTTP请求并返回响应头
 func DoRequestWithOptions(method string, url string, opts []RequestOption, body io.Reader) (statusCode int, content []byte, respHeaders http.Header, err error) {
+	statusCode, content, respHeaders, _, err = doRequest(method, url, opts, body)
+	return
+}
+
+// DoRequestWithTrailers 进行HTTP请求并返回响应头以及读取响应体后收到的 trailer
+func DoRequestWithTrailers(method string, url string, opts []RequestOption, body io.Reader) (statusCode int, content []byte, headers http.Header, trailers http.Header, err error) {
+	return doRequest(method, url, opts, body)
+}
+
+func doRequest(method string, url string, opts []RequestOption, body io.Reader) (statusCode int, content []byte, respHeaders http.Header, trailers http.Header, err error) {
 	req, err := http.NewRequest(method, url, body)
 	if err != nil {
-		return 0, nil, nil, fmt.Errorf("build new request fail: %w", err)
+		return 0, nil, nil, nil, fmt.Errorf("build new request fail: %w", err)
 	}
 
-	for _, opt := range opts {
+	for _, opt := range withDefaultOptions(opts) {
 		req = opt(req)
 	}
 
-	resp, err := DefaultClient().Do(req)
+	settings := settingsOf(req)
+	if settings.err != nil {
+		return 0, nil, nil, nil, settings.err
+	}
+	if settings.timeout > 0 {
+		// cancel after response body read
+		ctx, cancel := context.WithTimeout(req.Context(), settings.timeout)
+		defer cancel()
+		req = req.WithContext(ctx)
+	}
+	if cb := settings.circuitBreaker; cb != nil {
+		if !cb.Allow() {
+			return -1, nil, nil, nil, &CircuitBreakerError{OpenedAt: cb.OpenedAt()}
+		}
+		defer func() { cb.RecordResult(err == nil && statusCode < http.StatusInternalServerError) }()
+	}
+
+	if len(settings.middlewares) == 0 {
+		statusCode, content, respHeaders, err = send(req, settings, &trailers)
+		return statusCode, content, respHeaders, trailers, err
+	}
+
+	ctx, cancel := context.WithCancel(req.Context())
+	defer cancel()
+	req = req.WithContext(ctx)
+	reqCtx := newRequestContext(req)
+	reqCtx.cancel = cancel
+
+	var sent bool
+	statusCode, content, respHeaders, err = ChainMiddleware(settings.middlewares...)(reqCtx, func() (int, []byte, http.Header, error) {
+		if sent && req.GetBody != nil { // middleware may call next more than once, rewind body
+			body, err := req.GetBody()
+			if err != nil {
+				return -1, nil, nil, fmt.Errorf("rewind request body fail: %w", err)
+			}
+			req.Body = body
+		}
+		sent = true
+		return send(req, settings, &trailers)
+	})
+	return statusCode, content, respHeaders, trailers, err
+}
+
+// send send request and read response body, trailers received after body are stored in trailers
+func send(req *http.Request, settings *requestSettings, trailers *http.Header) (statusCode int, content []byte, respHeaders http.Header, err error) {
+	client, release := settings.client()
+	defer release()
+
+	resp, err := client.Do(req)
 	if err != nil {
 		return -1, nil, nil, err
 	}
@@ -107,5 +206,80 @@ func DoRequestWithOptions(method string, url string, opts []RequestOption, body
 	if err != nil {
 		return -1, nil, nil, err
 	}
+	*trailers = resp.Trailer
+
+	for _, intercept := range settings.interceptors {
+		if content, err = intercept(resp.StatusCode, content, resp.Header); err != nil {
+			return resp.StatusCode, nil, resp.Header, fmt.Errorf("intercept response fail: %w", err)
+		}
+	}
+
+	var errs []error
+	for _, validate := range settings.validators {
+		if err := validate(resp.StatusCode, content, resp.Header); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return resp.StatusCode, content, resp.Header, &ValidationError{StatusCode: resp.StatusCode, Body: content, Err: errors.Join(errs...)}
+	}
 	return resp.StatusCode, content, resp.Header, nil
 }
+
+// DoRequestRaw 进行HTTP请求并返回原始响应，不读取响应体
+// 调用方必须调用 resp.Body.Close()，WithResponseInterceptor 对原始响应不生效
+func DoRequestRaw(method string, url string, opts []RequestOption, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequest(method, url, body)
+	if err != nil {
+		return nil, fmt.Errorf("build new request fail: %w", err)
+	}
+
+	for _, opt := range withDefaultOptions(opts) {
+		req = opt(req)
+	}
+
+	settings := settingsOf(req)
+	if settings.err != nil {
+		return nil, settings.err
+	}
+	cancel := context.CancelFunc(func() {})
+	if settings.timeout > 0 {
+		var ctx context.Context
+		ctx, cancel = context.WithTimeout(req.Context(), settings.timeout)
+		req = req.WithContext(ctx)
+	}
+	if cb := settings.circuitBreaker; cb != nil && !cb.Allow() {
+		cancel()
+		return nil, &CircuitBreakerError{OpenedAt: cb.OpenedAt()}
+	}
+
+	client, release := settings.client()
+	resp, err := client.Do(req)
+	if cb := settings.circuitBreaker; cb != nil {
+		cb.RecordResult(err == nil && resp.StatusCode < http.StatusInternalServerError)
+	}
+	if err != nil {
+		cancel()
+		release()
+		return nil, err
+	}
+	// timeout covers reading body, cancel when body closed
+	resp.Body = &cancelReadCloser{ReadCloser: resp.Body, cancel: func() { cancel(); release() }}
+	return resp, nil
+}
+
+// DoRequestRawWithContext 进行HTTP请求并返回原始响应，调用方必须调用 resp.Body.Close()
+func DoRequestRawWithContext(ctx context.Context, method string, url string, opts []RequestOption, body io.Reader) (*http.Response, error) {
+	return DoRequestRaw(method, url, append(opts, WithContext(ctx)), body)
+}
+
+// cancelReadCloser cancel context after closed
+type cancelReadCloser struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c *cancelReadCloser) Close() error {
+	defer c.cancel()
+	return c.ReadCloser.Close()
+}
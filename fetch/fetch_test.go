@@ -1,7 +1,14 @@
 package fetch
 
 import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 )
 
 func TestGet(t *testing.T) {
@@ -12,3 +19,110 @@ func TestGet(t *testing.T) {
 		t.Logf("got data: %v", string(data))
 	}
 }
+
+func TestDoRequestRaw(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Trailer", "X-Checksum")
+		_, _ = io.WriteString(w, "hello world")
+		w.Header().Set("X-Checksum", "abc")
+	}))
+	defer server.Close()
+
+	resp, err := DoRequestRawWithContext(context.Background(), http.MethodGet, server.URL, []RequestOption{WithTimeout(time.Second)}, nil)
+	if err != nil {
+		t.Fatalf("request fail: %s", err)
+	}
+	defer resp.Body.Close() // nolint
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil || string(body) != "hello world" {
+		t.Errorf("unexpected body: %q, err: %v", body, err)
+	}
+	if checksum := resp.Trailer.Get("X-Checksum"); checksum != "abc" {
+		t.Errorf("expect trailer X-Checksum: abc, got: %q", checksum)
+	}
+}
+
+func TestDoRequestWithTrailers(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Trailer", "X-Checksum, Grpc-Status")
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = io.WriteString(w, "hello world")
+		w.Header().Set("X-Checksum", "abc")
+		w.Header().Set("Grpc-Status", "0")
+	}))
+	defer server.Close()
+
+	statusCode, content, headers, trailers, err := DoRequestWithTrailers(http.MethodGet, server.URL, nil, nil)
+	if err != nil || statusCode != http.StatusOK || string(content) != "hello world" {
+		t.Fatalf("unexpected response: %d %q, err: %v", statusCode, content, err)
+	}
+	if headers.Get("Content-Type") != "text/plain" || headers.Get("X-Checksum") != "" {
+		t.Errorf("unexpected headers: %v", headers)
+	}
+	if trailers.Get("X-Checksum") != "abc" || trailers.Get("Grpc-Status") != "0" {
+		t.Errorf("unexpected trailers: %v", trailers)
+	}
+
+	// trailers of response passed through middleware
+	_, _, _, trailers, err = DoRequestWithTrailers(http.MethodGet, server.URL,
+		[]RequestOption{WithMiddleware(WithLogging(new(recordLogger)))}, nil)
+	if err != nil || trailers.Get("X-Checksum") != "abc" {
+		t.Errorf("unexpected trailers with middleware: %v, err: %v", trailers, err)
+	}
+}
+
+func TestDefaultClient_verifyTLS(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	var certErr *tls.CertificateVerificationError
+	if _, err := Get(server.URL); !errors.As(err, &certErr) {
+		t.Errorf("expect default client reject self-signed certificate, got: %v", err)
+	}
+
+	client := NewInsecureClient(time.Second)
+	if client.Timeout != time.Second {
+		t.Errorf("expect timeout 1s, got %s", client.Timeout)
+	}
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("insecure client request fail: %s", err)
+	}
+	defer resp.Body.Close()
+	if data, _ := io.ReadAll(resp.Body); string(data) != "ok" {
+		t.Errorf("unexpected response: %q", data)
+	}
+}
+
+func TestRegisterDefaultOptions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.Header.Get("User-Agent") + "|" + r.Header.Get("Accept")))
+	}))
+	defer server.Close()
+
+	RegisterDefaultOptions(WithSetHeader("User-Agent", "pkg-test/1.0"), WithSetHeader("Accept", "application/json"))
+	defer UnregisterDefaultOptions()
+
+	if data, err := Get(server.URL); err != nil {
+		t.Fatalf("request fail: %s", err)
+	} else if string(data) != "pkg-test/1.0|application/json" {
+		t.Errorf("expect default headers, got %q", data)
+	}
+
+	// per-call options override default ones
+	if data, err := Get(server.URL, WithSetHeader("User-Agent", "override")); err != nil {
+		t.Fatalf("request fail: %s", err)
+	} else if string(data) != "override|application/json" {
+		t.Errorf("expect overridden user agent, got %q", data)
+	}
+
+	UnregisterDefaultOptions()
+	if data, err := Get(server.URL); err != nil {
+		t.Fatalf("request fail: %s", err)
+	} else if string(data) != "Go-http-client/1.1|" {
+		t.Errorf("expect no default headers after unregister, got %q", data)
+	}
+}
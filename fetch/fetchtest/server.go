@@ -0,0 +1,147 @@
+// Package fetchtest provides a declarative mock http server for testing api clients
+package fetchtest
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// NewMockServer start a mock server, Close must be called after use
+func NewMockServer() *MockServer {
+	s := &MockServer{handlers: make(map[string]http.HandlerFunc)}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	return s
+}
+
+// MockServer http server replying registered handlers and expectations,
+// requests matching neither are answered with 404 and reported by AssertExpectations
+type MockServer struct {
+	*httptest.Server
+
+	mu           sync.Mutex
+	handlers     map[string]http.HandlerFunc // method path -> handler
+	expectations []*RequestExpectation
+	unexpected   []string
+}
+
+// OnGet register handler for GET request on path
+func (s *MockServer) OnGet(path string, handler func(w http.ResponseWriter, r *http.Request)) {
+	s.On(http.MethodGet, path, handler)
+}
+
+// OnPost register handler for POST request on path
+func (s *MockServer) OnPost(path string, handler func(w http.ResponseWriter, r *http.Request)) {
+	s.On(http.MethodPost, path, handler)
+}
+
+// On register handler for request with method on path, replacing handler registered before
+func (s *MockServer) On(method, path string, handler func(w http.ResponseWriter, r *http.Request)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers[method+" "+path] = handler
+}
+
+// Expect expect request with method on path, replies 200 with empty body unless handler registered or Reply set
+func (s *MockServer) Expect(method, path string) *RequestExpectation {
+	e := &RequestExpectation{method: method, path: path, statusCode: http.StatusOK, headers: make(http.Header)}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.expectations = append(s.expectations, e)
+	return e
+}
+
+// AssertExpectations report expectations not met and requests not expected, usually called in t.Cleanup
+func (s *MockServer) AssertExpectations(t testing.TB) {
+	t.Helper()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, e := range s.expectations {
+		switch {
+		case e.times > 0 && e.calls != e.times:
+			t.Errorf("expect %s %s called %d times, got %d", e.method, e.path, e.times, e.calls)
+		case e.times == 0 && e.calls == 0:
+			t.Errorf("expect %s %s called, got none", e.method, e.path)
+		}
+	}
+	for _, req := range s.unexpected {
+		t.Errorf("unexpected request: %s", req)
+	}
+}
+
+func (s *MockServer) serve(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	var matched *RequestExpectation
+	for _, e := range s.expectations {
+		if e.match(r) {
+			e.calls++
+			matched = e
+			break
+		}
+	}
+	handler, ok := s.handlers[r.Method+" "+r.URL.Path]
+	if !ok && matched == nil {
+		s.unexpected = append(s.unexpected, r.Method+" "+r.URL.String())
+	}
+	s.mu.Unlock()
+
+	switch {
+	case ok:
+		handler(w, r)
+	case matched != nil:
+		matched.reply(w)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// RequestExpectation expected request
+type RequestExpectation struct {
+	method, path string
+	headers      http.Header // headers request must carry
+	times        int         // expected calls, zero means at least once
+
+	statusCode int
+	body       []byte
+
+	calls int // guarded by MockServer.mu
+}
+
+// WithHeader match only request carrying header key with value
+func (e *RequestExpectation) WithHeader(key, value string) *RequestExpectation {
+	e.headers.Add(key, value)
+	return e
+}
+
+// Times expect request called exactly n times
+func (e *RequestExpectation) Times(n int) *RequestExpectation {
+	e.times = n
+	return e
+}
+
+// Reply reply matched request with status code and body
+func (e *RequestExpectation) Reply(statusCode int, body string) *RequestExpectation {
+	e.statusCode, e.body = statusCode, []byte(body)
+	return e
+}
+
+func (e *RequestExpectation) match(r *http.Request) bool {
+	if r.Method != e.method || r.URL.Path != e.path {
+		return false
+	}
+	for key := range e.headers {
+		if fmt.Sprint(r.Header.Values(key)) != fmt.Sprint(e.headers.Values(key)) {
+			return false
+		}
+	}
+	return true
+}
+
+func (e *RequestExpectation) reply(w http.ResponseWriter) {
+	w.WriteHeader(e.statusCode)
+	_, _ = w.Write(e.body)
+}
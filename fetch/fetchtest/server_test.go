@@ -0,0 +1,73 @@
+package fetchtest
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/tr1v3r/pkg/fetch"
+)
+
+func TestMockServer(t *testing.T) {
+	server := NewMockServer()
+	defer server.Close()
+	t.Cleanup(func() { server.AssertExpectations(t) })
+
+	server.OnPost("/users", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write(body)
+	})
+	server.Expect(http.MethodGet, "/users/1").WithHeader("Authorization", "Bearer token").Times(2).
+		Reply(http.StatusOK, `{"id":1}`)
+	server.Expect(http.MethodPost, "/users")
+
+	for i := 0; i < 2; i++ {
+		data, err := fetch.Get(server.URL+"/users/1", fetch.WithHeader("Authorization", "Bearer token"))
+		if err != nil || string(data) != `{"id":1}` {
+			t.Errorf("unexpected response: %s, err: %v", data, err)
+		}
+	}
+	statusCode, data, _, err := fetch.DoRequestWithOptions(http.MethodPost, server.URL+"/users", nil, strings.NewReader(`{"name":"a"}`))
+	if err != nil || statusCode != http.StatusCreated || string(data) != `{"name":"a"}` {
+		t.Errorf("unexpected response: %d %s, err: %v", statusCode, data, err)
+	}
+}
+
+func TestMockServer_AssertExpectations(t *testing.T) {
+	server := NewMockServer()
+	defer server.Close()
+
+	server.Expect(http.MethodGet, "/called").Times(2)
+	server.Expect(http.MethodGet, "/missing")
+
+	_, _ = fetch.Get(server.URL + "/called")
+	statusCode, _, _, _ := fetch.DoRequestWithOptions(http.MethodGet, server.URL+"/unknown", nil, nil)
+	if statusCode != http.StatusNotFound {
+		t.Errorf("unexpected request should get 404, got: %d", statusCode)
+	}
+
+	var rec recorder
+	server.AssertExpectations(&rec)
+	if len(rec.errors) != 3 {
+		t.Fatalf("expect 3 failures, got: %q", rec.errors)
+	}
+	for i, expected := range []string{"/called called 2 times, got 1", "/missing called, got none", "unexpected request: GET /unknown"} {
+		if !strings.Contains(rec.errors[i], expected) {
+			t.Errorf("expect failure %q, got: %q", expected, rec.errors[i])
+		}
+	}
+}
+
+// recorder testing.TB recording failures
+type recorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recorder) Helper() {}
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,89 @@
+package fetch
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// NewHealthChecker return a health checker polls url every interval, and records results into circuit breaker
+func NewHealthChecker(url string, interval time.Duration, cb *CircuitBreaker) *HealthChecker {
+	return &HealthChecker{url: url, interval: interval, cb: cb}
+}
+
+// HealthChecker health checker
+type HealthChecker struct {
+	url      string
+	interval time.Duration
+	cb       *CircuitBreaker
+
+	mu        sync.RWMutex
+	ok        bool
+	latency   time.Duration
+	checkedAt time.Time
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// Start start polling in background until ctx done or Stop called
+func (h *HealthChecker) Start(ctx context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+
+	h.mu.Lock()
+	h.cancel, h.done = cancel, done
+	h.mu.Unlock()
+
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(h.interval)
+		defer ticker.Stop()
+		for {
+			h.check(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop stop polling and wait for running check finished
+func (h *HealthChecker) Stop() {
+	h.mu.RLock()
+	cancel, done := h.cancel, h.done
+	h.mu.RUnlock()
+
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+}
+
+// LastStatus return result of last check
+func (h *HealthChecker) LastStatus() (ok bool, latency time.Duration, checkedAt time.Time) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.ok, h.latency, h.checkedAt
+}
+
+func (h *HealthChecker) check(ctx context.Context) {
+	start := time.Now()
+	statusCode, _, _, err := DoRequestWithOptions(http.MethodGet, h.url, []RequestOption{WithContext(ctx)}, nil)
+	if ctx.Err() != nil { // stopped while checking
+		return
+	}
+	ok := err == nil && statusCode >= http.StatusOK && statusCode < http.StatusMultipleChoices
+
+	h.mu.Lock()
+	h.ok, h.latency, h.checkedAt = ok, time.Since(start), start
+	h.mu.Unlock()
+
+	if h.cb != nil {
+		h.cb.RecordResult(ok)
+	}
+}
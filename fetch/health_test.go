@@ -0,0 +1,54 @@
+package fetch
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHealthChecker(t *testing.T) {
+	var healthy int32 = 1
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&healthy) == 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	cb := NewCircuitBreaker(2, time.Minute)
+	checker := NewHealthChecker(server.URL, 5*time.Millisecond, cb)
+	checker.Start(context.Background())
+	defer checker.Stop()
+
+	waitState := func(expected CircuitState, ok bool) {
+		t.Helper()
+		for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+			if last, _, _ := checker.LastStatus(); cb.State() == expected && last == ok {
+				return
+			}
+		}
+		last, _, _ := checker.LastStatus()
+		t.Fatalf("expect circuit breaker %s with last status %t, got %s with %t", expected, ok, cb.State(), last)
+	}
+
+	waitState(StateClosed, true)
+	if _, latency, checkedAt := checker.LastStatus(); latency <= 0 || checkedAt.IsZero() {
+		t.Errorf("unexpected last status: latency %s, checked at %s", latency, checkedAt)
+	}
+
+	atomic.StoreInt32(&healthy, 0)
+	waitState(StateOpen, false)
+
+	atomic.StoreInt32(&healthy, 1)
+	waitState(StateClosed, true)
+
+	checker.Stop()
+	_, _, checkedAt := checker.LastStatus()
+	time.Sleep(20 * time.Millisecond)
+	if _, _, last := checker.LastStatus(); !last.Equal(checkedAt) {
+		t.Errorf("checker should not poll after stopped")
+	}
+}
@@ -0,0 +1,29 @@
+package fetch
+
+import (
+	"crypto/tls"
+	"net/http"
+)
+
+// NewHTTP2Client return a client speaks HTTP/2 over cleartext (h2c) with prior knowledge
+// requests to servers only speak HTTP/1.1 fail instead of downgrading
+func NewHTTP2Client() *http.Client {
+	var protocols http.Protocols
+	protocols.SetUnencryptedHTTP2(true)
+
+	transport := newTransport(http.ProxyFromEnvironment, false)
+	transport.Protocols = &protocols
+	return &http.Client{Timeout: defaultTimeout, Transport: transport}
+}
+
+// NewHTTP2TLSClient return a client speaks HTTP/2 over TLS only
+// requests to servers not negotiating h2 fail instead of downgrading
+func NewHTTP2TLSClient() *http.Client {
+	var protocols http.Protocols
+	protocols.SetHTTP2(true)
+
+	transport := newTransport(http.ProxyFromEnvironment, false)
+	transport.Protocols = &protocols
+	transport.TLSClientConfig = &tls.Config{NextProtos: []string{"h2"}}
+	return &http.Client{Timeout: defaultTimeout, Transport: transport}
+}
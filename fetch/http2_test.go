@@ -0,0 +1,69 @@
+package fetch
+
+import (
+	"crypto/tls"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNewHTTP2Client(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { _, _ = io.WriteString(w, r.Proto) })
+
+	server := httptest.NewUnstartedServer(handler)
+	server.Config.Protocols = new(http.Protocols)
+	server.Config.Protocols.SetHTTP1(true)
+	server.Config.Protocols.SetUnencryptedHTTP2(true)
+	server.Start()
+	defer server.Close()
+
+	resp, err := NewHTTP2Client().Get(server.URL)
+	if err != nil {
+		t.Fatalf("request h2c server fail: %s", err)
+	}
+	defer resp.Body.Close() // nolint
+	if body, _ := io.ReadAll(resp.Body); resp.ProtoMajor != 2 || string(body) != "HTTP/2.0" {
+		t.Errorf("expect HTTP/2 response, got %s with body %q", resp.Proto, body)
+	}
+
+	http1 := httptest.NewServer(handler)
+	defer http1.Close()
+	if resp, err := NewHTTP2Client().Get(http1.URL); err == nil {
+		resp.Body.Close() // nolint
+		t.Errorf("request HTTP/1.1 only server should fail, got %s", resp.Proto)
+	}
+}
+
+func TestNewHTTP2TLSClient(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { _, _ = io.WriteString(w, r.Proto) })
+
+	server := httptest.NewUnstartedServer(handler)
+	server.EnableHTTP2 = true
+	server.StartTLS()
+	defer server.Close()
+
+	client := NewHTTP2TLSClient()
+	client.Transport.(*http.Transport).TLSClientConfig.RootCAs = server.Client().Transport.(*http.Transport).TLSClientConfig.RootCAs
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("request h2 server fail: %s", err)
+	}
+	resp.Body.Close() // nolint
+	if resp.ProtoMajor != 2 {
+		t.Errorf("expect HTTP/2 response, got %s", resp.Proto)
+	}
+
+	http1 := httptest.NewUnstartedServer(handler)
+	http1.TLS = &tls.Config{NextProtos: []string{"http/1.1"}}
+	http1.Config.ErrorLog = log.New(io.Discard, "", 0) // handshake error expected
+	http1.StartTLS()
+	defer http1.Close()
+
+	client.Transport.(*http.Transport).TLSClientConfig.RootCAs = http1.Client().Transport.(*http.Transport).TLSClientConfig.RootCAs
+	if resp, err := client.Get(http1.URL); err == nil {
+		resp.Body.Close() // nolint
+		t.Errorf("request HTTP/1.1 only server should fail, got %s", resp.Proto)
+	}
+}
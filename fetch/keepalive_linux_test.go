@@ -0,0 +1,48 @@
+package fetch
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httptrace"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestWithKeepAlive(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	// sockopt return keep-alive socket options of connection used by request
+	sockopt := func(opts ...RequestOption) (keepAlive, idle, interval int) {
+		var conn net.Conn
+		ctx := httptrace.WithClientTrace(context.Background(), &httptrace.ClientTrace{
+			GotConn: func(info httptrace.GotConnInfo) { conn = info.Conn },
+		})
+		resp, err := DoRequestRawWithContext(ctx, http.MethodGet, server.URL, opts, nil)
+		if err != nil {
+			t.Fatalf("request fail: %s", err)
+		}
+		defer func() { _ = resp.Body.Close() }()
+
+		raw, err := conn.(*net.TCPConn).SyscallConn()
+		if err != nil {
+			t.Fatalf("get raw conn fail: %s", err)
+		}
+		_ = raw.Control(func(fd uintptr) {
+			keepAlive, _ = syscall.GetsockoptInt(int(fd), syscall.SOL_SOCKET, syscall.SO_KEEPALIVE)
+			idle, _ = syscall.GetsockoptInt(int(fd), syscall.IPPROTO_TCP, syscall.TCP_KEEPIDLE)
+			interval, _ = syscall.GetsockoptInt(int(fd), syscall.IPPROTO_TCP, syscall.TCP_KEEPINTVL)
+		})
+		return keepAlive, idle, interval
+	}
+
+	if keepAlive, idle, interval := sockopt(WithKeepAlive(7 * time.Second)); keepAlive == 0 || idle != 7 || interval != 7 {
+		t.Errorf("expect keep-alive probes every 7s, got enabled %d, idle %d, interval %d", keepAlive, idle, interval)
+	}
+	if keepAlive, _, _ := sockopt(WithKeepAlive(0)); keepAlive != 0 {
+		t.Errorf("expect keep-alive disabled")
+	}
+}
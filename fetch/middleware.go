@@ -0,0 +1,59 @@
+package fetch
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"time"
+)
+
+// RequestContext request metadata passed to middleware
+type RequestContext struct {
+	Method string
+	URL    string
+	Header http.Header
+	Body   []byte // snapshot of request body, nil if body is not replayable
+
+	cancel context.CancelFunc // cancel request, nil if middleware called outside DoRequestWithOptions
+}
+
+// newRequestContext build request context from req, body is snapshotted only when req.GetBody set
+func newRequestContext(req *http.Request) RequestContext {
+	ctx := RequestContext{Method: req.Method, URL: req.URL.String(), Header: req.Header.Clone()}
+	if req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			ctx.Body, _ = io.ReadAll(body)
+			_ = body.Close()
+		}
+	}
+	return ctx
+}
+
+// Middleware wrap sending request, next sends request and returns response, it can be called more than once
+type Middleware func(ctx RequestContext, next func() (int, []byte, http.Header, error)) (int, []byte, http.Header, error)
+
+// ChainMiddleware chain middlewares into one, the first one is outermost
+func ChainMiddleware(middlewares ...Middleware) Middleware {
+	return func(ctx RequestContext, next func() (int, []byte, http.Header, error)) (int, []byte, http.Header, error) {
+		for i := len(middlewares) - 1; i >= 0; i-- {
+			mw, inner := middlewares[i], next
+			next = func() (int, []byte, http.Header, error) { return mw(ctx, inner) }
+		}
+		return next()
+	}
+}
+
+// RequestLogger log request result
+type RequestLogger interface {
+	LogRequest(method, url string, statusCode int, duration time.Duration, err error)
+}
+
+// WithLogging return middleware logs every request with logger
+func WithLogging(logger RequestLogger) Middleware {
+	return func(ctx RequestContext, next func() (int, []byte, http.Header, error)) (int, []byte, http.Header, error) {
+		start := time.Now()
+		statusCode, content, headers, err := next()
+		logger.LogRequest(ctx.Method, ctx.URL, statusCode, time.Since(start), err)
+		return statusCode, content, headers, err
+	}
+}
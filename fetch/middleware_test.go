@@ -0,0 +1,89 @@
+package fetch
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type recordLogger struct{ records []string }
+
+func (l *recordLogger) LogRequest(method, url string, statusCode int, _ time.Duration, err error) {
+	l.records = append(l.records, fmt.Sprintf("%s %s %d %v", method, url, statusCode, err))
+}
+
+func TestChainMiddleware(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	var trace []string
+	tracing := func(name string) Middleware {
+		return func(ctx RequestContext, next func() (int, []byte, http.Header, error)) (int, []byte, http.Header, error) {
+			trace = append(trace, name+":"+string(ctx.Body))
+			return next()
+		}
+	}
+
+	logger := new(recordLogger)
+	statusCode, _, _, err := DoRequestWithOptions(http.MethodPost, server.URL+"/path?q=1",
+		[]RequestOption{WithMiddleware(WithLogging(logger), tracing("a")), WithMiddleware(tracing("b"))}, strings.NewReader("body"))
+	if err != nil || statusCode != http.StatusCreated {
+		t.Fatalf("request fail: status %d, err %v", statusCode, err)
+	}
+
+	if expected := "POST " + server.URL + "/path?q=1 201 <nil>"; len(logger.records) != 1 || logger.records[0] != expected {
+		t.Errorf("unexpected log records: %v, expect: %s", logger.records, expected)
+	}
+	if strings.Join(trace, " ") != "a:body b:body" {
+		t.Errorf("unexpected middleware order: %v", trace)
+	}
+}
+
+func TestChainMiddleware_concurrent(t *testing.T) {
+	var mu sync.Mutex
+	tags := make(map[string][]string) // url -> tags appended by middlewares
+
+	tagging := func(tag string) Middleware {
+		return func(ctx RequestContext, next func() (int, []byte, http.Header, error)) (int, []byte, http.Header, error) {
+			mu.Lock()
+			tags[ctx.URL] = append(tags[ctx.URL], tag)
+			mu.Unlock()
+			return next()
+		}
+	}
+	chain := ChainMiddleware(tagging("1"), tagging("2"), tagging("3"), tagging("4"), tagging("5"))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			url := fmt.Sprintf("http://example.com/%d", i)
+			statusCode, _, _, _ := chain(RequestContext{URL: url}, func() (int, []byte, http.Header, error) {
+				mu.Lock()
+				tags[url] = append(tags[url], "send")
+				mu.Unlock()
+				return http.StatusOK, nil, nil, nil
+			})
+			if statusCode != http.StatusOK {
+				t.Errorf("unexpected status code: %d", statusCode)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if len(tags) != 50 {
+		t.Errorf("expect 50 requests tagged, got %d", len(tags))
+	}
+	for url, got := range tags {
+		if strings.Join(got, ",") != "1,2,3,4,5,send" {
+			t.Errorf("%s: unexpected tags: %v", url, got)
+		}
+	}
+}
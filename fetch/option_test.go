@@ -0,0 +1,319 @@
+package fetch
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWithResponseInterceptor(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(base64.StdEncoding.EncodeToString([]byte("hello world"))))
+	}))
+	defer server.Close()
+
+	decode := func(statusCode int, body []byte, headers http.Header) ([]byte, error) {
+		return base64.StdEncoding.DecodeString(string(body))
+	}
+	exclaim := func(statusCode int, body []byte, headers http.Header) ([]byte, error) {
+		return append(body, '!'), nil
+	}
+
+	data, err := Get(server.URL, WithResponseInterceptor(decode), WithResponseInterceptor(exclaim))
+	if err != nil {
+		t.Fatalf("get fail: %s", err)
+	}
+	if string(data) != "hello world!" {
+		t.Errorf("unexpected data: %q", data)
+	}
+
+	errBroken := errors.New("broken")
+	_, err = Get(server.URL, WithResponseInterceptor(func(int, []byte, http.Header) ([]byte, error) { return nil, errBroken }))
+	if !errors.Is(err, errBroken) {
+		t.Errorf("expect interceptor error, got: %v", err)
+	}
+}
+
+func TestWithTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			time.Sleep(100 * time.Millisecond)
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	if _, err := Get(server.URL+"/slow", WithTimeout(10*time.Millisecond)); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expect deadline exceeded, got: %v", err)
+	}
+
+	_, _ = Get(server.URL, WithTimeout(time.Second)) // warm up connection
+	before := runtime.NumGoroutine()
+	for i := 0; i < 1000; i++ {
+		if _, err := Get(server.URL, WithTimeout(time.Second)); err != nil {
+			t.Fatalf("get fail: %s", err)
+		}
+	}
+	time.Sleep(10 * time.Millisecond)
+	if after := runtime.NumGoroutine(); after > before+2 {
+		t.Errorf("goroutines leaked: %d before, %d after", before, after)
+	}
+}
+
+func TestWithQueryParamsMulti(t *testing.T) {
+	var rawQuery string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rawQuery = r.URL.RawQuery
+	}))
+	defer server.Close()
+
+	_, err := Get(server.URL+"?page=1&sort=asc",
+		WithQueryParamsMulti(map[string][]string{"tag": {"a", "b"}, "sort": {"desc"}}),
+		WithQueryParams(map[string]string{"page": "2"}),
+		WithQueryParams(map[string]string{"page": "3"}),
+	)
+	if err != nil {
+		t.Fatalf("request fail: %s", err)
+	}
+	if expected := "page=3&sort=asc&sort=desc&tag=a&tag=b"; rawQuery != expected {
+		t.Errorf("unexpected query: %s, expect: %s", rawQuery, expected)
+	}
+}
+
+func TestWithAccept(t *testing.T) {
+	for _, testcase := range []struct {
+		opts     []RequestOption
+		expected string
+	}{
+		{[]RequestOption{WithAcceptJSON()}, "application/json"},
+		{[]RequestOption{WithAcceptXML()}, "application/xml"},
+		{[]RequestOption{WithAcceptVersion("application/vnd.api+json", "3")}, "application/vnd.api+json; version=3"},
+		{[]RequestOption{WithAcceptJSON(), WithAcceptXML(), WithAccept("*/*")}, "application/json, application/xml, */*"},
+	} {
+		req, _ := http.NewRequest(http.MethodGet, "http://example.com", nil)
+		for _, opt := range testcase.opts {
+			req = opt(req)
+		}
+		if accept := req.Header.Values("Accept"); len(accept) != 1 || accept[0] != testcase.expected {
+			t.Errorf("unexpected Accept header: %q, expect: %q", accept, testcase.expected)
+		}
+	}
+
+	// option reused for fresh requests must not accumulate
+	json, xml := WithAcceptJSON(), WithAcceptXML()
+	for i := 0; i < 2; i++ {
+		req, _ := http.NewRequest(http.MethodGet, "http://example.com", nil)
+		req = xml(json(req))
+		if accept := req.Header.Get("Accept"); accept != "application/json, application/xml" {
+			t.Errorf("request %d: unexpected Accept header of reused options: %q", i, accept)
+		}
+	}
+}
+
+func TestWithProxy(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("direct"))
+	}))
+	defer target.Close()
+
+	var proxied []string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = append(proxied, r.URL.String())
+		_, _ = w.Write([]byte("proxied"))
+	}))
+	defer proxy.Close()
+
+	if data, err := Get(target.URL, WithProxy(proxy.URL)); err != nil || string(data) != "proxied" {
+		t.Errorf("request should go through proxy, got: %q, err: %v", data, err)
+	}
+	if len(proxied) != 1 || proxied[0] != target.URL+"/" {
+		t.Errorf("unexpected proxied requests: %v", proxied)
+	}
+	if _, err := Get(target.URL, WithProxy("://bad")); err == nil {
+		t.Errorf("expect error for invalid proxy url")
+	}
+
+	client := DefaultClient()
+	defer SetDefaultClient(client)
+	proxyURL, _ := url.Parse(proxy.URL)
+	SetDefaultClient(&http.Client{Transport: newTransport(http.ProxyURL(proxyURL), false)})
+
+	if data, err := Get(target.URL, WithNoProxy()); err != nil || string(data) != "direct" {
+		t.Errorf("request should bypass proxy, got: %q, err: %v", data, err)
+	}
+	if data, err := Get(target.URL); err != nil || string(data) != "proxied" {
+		t.Errorf("default client should be untouched, got: %q, err: %v", data, err)
+	}
+}
+
+func TestWithProxyAuth(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("direct"))
+	}))
+	defer target.Close()
+
+	expected := "Basic " + base64.StdEncoding.EncodeToString([]byte("user:p@ss"))
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Proxy-Authorization") != expected {
+			w.WriteHeader(http.StatusProxyAuthRequired)
+			return
+		}
+		_, _ = w.Write([]byte("proxied"))
+	}))
+	defer proxy.Close()
+
+	proxyURL, _ := url.Parse(proxy.URL)
+	proxyURL.User = url.UserPassword("user", "p@ss")
+
+	for _, testcase := range []struct {
+		opts     []RequestOption
+		expected int
+	}{
+		{[]RequestOption{WithProxy(proxy.URL)}, http.StatusProxyAuthRequired},
+		{[]RequestOption{WithProxy(proxy.URL), WithProxyAuth("user", "p@ss")}, http.StatusOK},
+		{[]RequestOption{WithProxyAuth("user", "p@ss"), WithProxy(proxy.URL)}, http.StatusOK},
+		{[]RequestOption{WithProxy(proxyURL.String())}, http.StatusOK},
+		{[]RequestOption{WithProxy(proxyURL.String()), WithProxyAuth("user", "wrong")}, http.StatusProxyAuthRequired},
+	} {
+		if statusCode, _, _, err := DoRequestWithOptions(http.MethodGet, target.URL, testcase.opts, nil); err != nil || statusCode != testcase.expected {
+			t.Errorf("expect status code %d, got %d, err: %v", testcase.expected, statusCode, err)
+		}
+	}
+
+	client := DefaultClient()
+	defer SetDefaultClient(client)
+	proxyURL.User = nil
+	SetDefaultClient(&http.Client{Transport: newTransport(http.ProxyURL(proxyURL), false)})
+
+	if data, err := Get(target.URL, WithProxyAuth("user", "p@ss")); err != nil || string(data) != "proxied" {
+		t.Errorf("expect authenticated to proxy of default client, got: %q, err: %v", data, err)
+	}
+	if data, err := Get(target.URL, WithProxyAuth("user", "p@ss"), WithNoProxy()); err != nil || string(data) != "direct" {
+		t.Errorf("expect request bypass proxy, got: %q, err: %v", data, err)
+	}
+}
+
+func TestWithDisableCompression(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "gzip")
+		gz := gzip.NewWriter(w)
+		_, _ = gz.Write([]byte("hello world"))
+		_ = gz.Close()
+	}))
+	defer server.Close()
+
+	data, err := Get(server.URL, WithDisableCompression(), WithHeader("Accept-Encoding", "gzip"))
+	if err != nil {
+		t.Fatalf("request fail: %s", err)
+	}
+	if !bytes.HasPrefix(data, []byte("\x1f\x8b")) {
+		t.Errorf("expect raw gzip body, got: %q", data)
+	}
+
+	if data, err := Get(server.URL); err != nil || string(data) != "hello world" {
+		t.Errorf("default client should decompress response, got: %q, err: %v", data, err)
+	}
+}
+
+func TestWithResponseValidation(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.URL.Query().Get("body")))
+	}))
+	defer server.Close()
+
+	// validate body is json object with string field "name" and number field "age"
+	errMissingName, errMissingAge := errors.New("missing name"), errors.New("missing age")
+	var calls int
+	validators := []RequestOption{
+		WithResponseValidation(func(statusCode int, body []byte, headers http.Header) error {
+			calls++
+			var v struct{ Name *string }
+			if json.Unmarshal(body, &v) != nil || v.Name == nil {
+				return errMissingName
+			}
+			return nil
+		}),
+		WithResponseValidation(func(statusCode int, body []byte, headers http.Header) error {
+			calls++
+			var v struct{ Age *float64 }
+			if json.Unmarshal(body, &v) != nil || v.Age == nil {
+				return errMissingAge
+			}
+			return nil
+		}),
+	}
+
+	get := func(body string) (int, []byte, error) {
+		statusCode, content, _, err := DoRequestWithOptions(http.MethodGet, server.URL, append(validators,
+			WithQueryParams(map[string]string{"body": body})), nil)
+		return statusCode, content, err
+	}
+
+	if _, _, err := get(`{"name":"foo","age":1}`); err != nil || calls != 2 {
+		t.Errorf("valid response should pass, got err %v, calls %d", err, calls)
+	}
+
+	calls = 0
+	_, _, err := get(`{"age":"1"}`)
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) || calls != 2 {
+		t.Fatalf("expect validation error after all validators run, got: %v, calls %d", err, calls)
+	}
+	if validationErr.StatusCode != http.StatusOK || string(validationErr.Body) != `{"age":"1"}` ||
+		!errors.Is(err, errMissingName) || !errors.Is(err, errMissingAge) {
+		t.Errorf("unexpected validation error: %+v", validationErr)
+	}
+}
+
+// recordingTransport round tripper counting requests
+type recordingTransport struct {
+	http.RoundTripper
+	calls int32
+}
+
+func (t *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	atomic.AddInt32(&t.calls, 1)
+	return t.RoundTripper.RoundTrip(req)
+}
+
+func TestWithCustomTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	rt := &recordingTransport{RoundTripper: http.DefaultTransport}
+	for i := 1; i <= 3; i++ {
+		if data, err := Get(server.URL, WithCustomTransport(rt)); err != nil || string(data) != "ok" {
+			t.Fatalf("request fail: %q, err: %v", data, err)
+		}
+		if n := atomic.LoadInt32(&rt.calls); n != int32(i) {
+			t.Errorf("expect transport called %d times, got %d", i, n)
+		}
+	}
+
+	// transport options can not be applied to custom round tripper, but it is still used
+	if data, err := Get(server.URL, WithCustomTransport(rt), WithProxy("http://127.0.0.1:1"), WithKeepAlive(time.Second)); err != nil || string(data) != "ok" {
+		t.Fatalf("request with transport options fail: %q, err: %v", data, err)
+	}
+	if n := atomic.LoadInt32(&rt.calls); n != 4 {
+		t.Errorf("expect custom transport used with transport options, called %d times", n)
+	}
+
+	if _, err := Get(server.URL); err != nil {
+		t.Fatalf("request fail: %s", err)
+	}
+	if n := atomic.LoadInt32(&rt.calls); n != 4 || DefaultClient().Transport == rt {
+		t.Errorf("default client should not be modified, transport called %d times", n)
+	}
+}
@@ -0,0 +1,288 @@
+package fetch
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"time"
+)
+
+// DefaultRetryConfig default retry config
+var DefaultRetryConfig = RetryConfig{
+	MaxAttempts:   3,
+	BaseDelay:     100 * time.Millisecond,
+	MaxDelay:      10 * time.Second,
+	RetryOnStatus: []int{http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout},
+}
+
+// RetryConfig retry config
+type RetryConfig struct {
+	MaxAttempts   int           // max attempts, including the first one
+	BaseDelay     time.Duration // delay before first retry, doubled on every retry
+	MaxDelay      time.Duration // max delay between two attempts
+	RetryOnStatus []int         // retry when response status code in list
+	Budget        time.Duration // max duration across all attempts, zero means unlimited
+
+	RateLimiter *RetryRateLimiter // limit retries sleeping concurrently, nil means unlimited
+}
+
+// NewRetryConfig return retry config based on DefaultRetryConfig
+func NewRetryConfig(opts ...RetryOption) RetryConfig {
+	config := DefaultRetryConfig
+	config.RetryOnStatus = append([]int(nil), DefaultRetryConfig.RetryOnStatus...)
+
+	c := &config
+	for _, opt := range opts {
+		c = opt(c)
+	}
+	return *c
+}
+
+// RetryOption ...
+type RetryOption func(*RetryConfig) *RetryConfig
+
+var (
+	// WithMaxAttempts set max attempts
+	WithMaxAttempts = func(attempts int) RetryOption {
+		return func(c *RetryConfig) *RetryConfig {
+			c.MaxAttempts = attempts
+			return c
+		}
+	}
+
+	// WithBaseDelay set delay before first retry
+	WithBaseDelay = func(delay time.Duration) RetryOption {
+		return func(c *RetryConfig) *RetryConfig {
+			c.BaseDelay = delay
+			return c
+		}
+	}
+
+	// WithMaxDelay set max delay between two attempts
+	WithMaxDelay = func(delay time.Duration) RetryOption {
+		return func(c *RetryConfig) *RetryConfig {
+			c.MaxDelay = delay
+			return c
+		}
+	}
+
+	// WithRetryBudget limit total duration across all attempts
+	WithRetryBudget = func(budget time.Duration) RetryOption {
+		return func(c *RetryConfig) *RetryConfig {
+			c.Budget = budget
+			return c
+		}
+	}
+
+	// WithRetryOnStatus retry only when response status code in codes, replacing list set before
+	WithRetryOnStatus = func(codes ...int) RetryOption {
+		return func(c *RetryConfig) *RetryConfig {
+			c.RetryOnStatus = append([]int(nil), codes...)
+			return c
+		}
+	}
+
+	// WithAddRetryOnStatus retry also when response status code in codes
+	WithAddRetryOnStatus = func(codes ...int) RetryOption {
+		return func(c *RetryConfig) *RetryConfig {
+			for _, code := range codes {
+				if !c.retryOnStatus(code) {
+					c.RetryOnStatus = append(c.RetryOnStatus[:len(c.RetryOnStatus):len(c.RetryOnStatus)], code)
+				}
+			}
+			return c
+		}
+	}
+
+	// WithRemoveRetryOnStatus do not retry when response status code in codes
+	WithRemoveRetryOnStatus = func(codes ...int) RetryOption {
+		return func(c *RetryConfig) *RetryConfig {
+			remained := make([]int, 0, len(c.RetryOnStatus))
+			for _, code := range c.RetryOnStatus {
+				if !slices.Contains(codes, code) {
+					remained = append(remained, code)
+				}
+			}
+			c.RetryOnStatus = remained
+			return c
+		}
+	}
+
+	// WithRetryRateLimiter share limiter between retries to avoid thundering herd
+	WithRetryRateLimiter = func(lim *RetryRateLimiter) RetryOption {
+		return func(c *RetryConfig) *RetryConfig {
+			c.RateLimiter = lim
+			return c
+		}
+	}
+)
+
+// NewRetryRateLimiter return a limiter allows at most maxConcurrentRetries retries waiting concurrently,
+// maxConcurrentRetries less than 1 is treated as 1
+func NewRetryRateLimiter(maxConcurrentRetries int) *RetryRateLimiter {
+	if maxConcurrentRetries < 1 {
+		maxConcurrentRetries = 1
+	}
+	return &RetryRateLimiter{tokens: make(chan struct{}, maxConcurrentRetries)}
+}
+
+// RetryRateLimiter retry rate limiter, a retry must hold a token while sleeping
+type RetryRateLimiter struct {
+	tokens chan struct{}
+}
+
+func (l *RetryRateLimiter) acquire(ctx context.Context) error {
+	select {
+	case l.tokens <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (l *RetryRateLimiter) release() { <-l.tokens }
+
+// RetryBudgetExceededError retry stopped because of budget exhausted
+type RetryBudgetExceededError struct {
+	Budget   time.Duration
+	Elapsed  time.Duration
+	Attempts int   // attempts made before budget exhausted
+	LastErr  error // error of last attempt, nil if it failed with retryable status code
+}
+
+func (e *RetryBudgetExceededError) Error() string {
+	msg := fmt.Sprintf("retry budget %s exceeded after %s, %d attempts", e.Budget, e.Elapsed, e.Attempts)
+	if e.LastErr != nil {
+		msg += ": " + e.LastErr.Error()
+	}
+	return msg
+}
+
+func (e *RetryBudgetExceededError) Unwrap() error { return e.LastErr }
+
+// IsRetryBudgetExceeded report whether err is caused by retry budget exhausted
+func IsRetryBudgetExceeded(err error) bool {
+	var budgetErr *RetryBudgetExceededError
+	return errors.As(err, &budgetErr)
+}
+
+// WithRetry call fn until it succeeds, attempts run out or retry budget exhausted
+func WithRetry(config RetryConfig, fn func() (int, []byte, http.Header, error)) (statusCode int, content []byte, respHeaders http.Header, err error) {
+	return WithRetryCtx(context.Background(), config, fn)
+}
+
+// WithRetryCtx same as WithRetry, stop waiting for next attempt and return ctx.Err() with last response when ctx done
+func WithRetryCtx(ctx context.Context, config RetryConfig, fn func() (int, []byte, http.Header, error)) (statusCode int, content []byte, respHeaders http.Header, err error) {
+	start := time.Now()
+	for attempt := 1; ; attempt++ {
+		statusCode, content, respHeaders, err = fn()
+		if !config.shouldRetry(statusCode, err) || attempt >= config.MaxAttempts {
+			return statusCode, content, respHeaders, err
+		}
+
+		delay := calculateBackoff(config, attempt)
+		if elapsed := time.Since(start); config.Budget > 0 && elapsed+delay > config.Budget {
+			return statusCode, content, respHeaders, &RetryBudgetExceededError{Budget: config.Budget, Elapsed: elapsed, Attempts: attempt, LastErr: err}
+		}
+		if sleepErr := config.sleep(ctx, delay); sleepErr != nil {
+			return statusCode, content, respHeaders, sleepErr
+		}
+	}
+}
+
+// DoRequestWithRetry send request with retry, body is replayed on every attempt:
+// body implementing io.Seeker is rewound to its offset at call, other body is read into memory first
+func DoRequestWithRetry(config RetryConfig, method string, url string, opts []RequestOption, body io.Reader) (statusCode int, content []byte, respHeaders http.Header, err error) {
+	replay, err := replayable(body)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	return WithRetry(config, func() (int, []byte, http.Header, error) {
+		body, err := replay()
+		if err != nil {
+			return 0, nil, nil, err
+		}
+		return DoRequestWithOptions(method, url, opts, body)
+	})
+}
+
+// replayable return function returning body from the beginning on every call
+func replayable(body io.Reader) (func() (io.Reader, error), error) {
+	switch b := body.(type) {
+	case nil:
+		return func() (io.Reader, error) { return nil, nil }, nil
+	case io.ReadSeeker:
+		offset, err := b.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return nil, fmt.Errorf("get body offset fail: %w", err)
+		}
+		return func() (io.Reader, error) {
+			if _, err := b.Seek(offset, io.SeekStart); err != nil {
+				return nil, fmt.Errorf("rewind body fail: %w", err)
+			}
+			if _, ok := b.(io.Closer); ok { // body is closed by client after sent
+				return struct{ io.Reader }{b}, nil
+			}
+			return b, nil
+		}, nil
+	default:
+		data, err := io.ReadAll(body)
+		if err != nil {
+			return nil, fmt.Errorf("read body fail: %w", err)
+		}
+		return func() (io.Reader, error) { return bytes.NewReader(data), nil }, nil
+	}
+}
+
+// sleep sleep before retry, holding a token of rate limiter if set, return ctx.Err() if ctx done before waking up
+func (c *RetryConfig) sleep(ctx context.Context, delay time.Duration) error {
+	if c.RateLimiter != nil {
+		if err := c.RateLimiter.acquire(ctx); err != nil {
+			return err
+		}
+		defer c.RateLimiter.release()
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (c *RetryConfig) shouldRetry(statusCode int, err error) bool {
+	return err != nil || c.retryOnStatus(statusCode)
+}
+
+// retryable report whether failure is worth retrying, response failed validation only when status code in RetryOnStatus
+func (c *RetryConfig) retryable(statusCode int, err error) bool {
+	if validationErr := (*ValidationError)(nil); errors.As(err, &validationErr) {
+		return c.retryOnStatus(validationErr.StatusCode)
+	}
+	return c.shouldRetry(statusCode, err)
+}
+
+func (c *RetryConfig) retryOnStatus(statusCode int) bool {
+	return slices.Contains(c.RetryOnStatus, statusCode)
+}
+
+// calculateBackoff return delay after attempt-th attempt
+func calculateBackoff(config RetryConfig, attempt int) time.Duration {
+	delay := config.BaseDelay
+	for i := 1; i < attempt; i++ {
+		if delay *= 2; config.MaxDelay > 0 && delay >= config.MaxDelay {
+			return config.MaxDelay
+		}
+	}
+	if config.MaxDelay > 0 && delay > config.MaxDelay {
+		return config.MaxDelay
+	}
+	return delay
+}
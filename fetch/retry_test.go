@@ -0,0 +1,267 @@
+package fetch
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWithRetry_budget(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	config := NewRetryConfig(WithMaxAttempts(10), WithBaseDelay(100*time.Millisecond), WithRetryBudget(200*time.Millisecond))
+	statusCode, _, _, err := WithRetry(config, func() (int, []byte, http.Header, error) {
+		return DoRequestWithOptions(http.MethodGet, server.URL, nil, nil)
+	})
+
+	var budgetErr *RetryBudgetExceededError
+	if !errors.As(err, &budgetErr) || !IsRetryBudgetExceeded(err) {
+		t.Fatalf("expect retry budget exceeded error, got: %v", err)
+	}
+	if budgetErr.Budget != 200*time.Millisecond || budgetErr.Attempts != 2 || budgetErr.LastErr != nil {
+		t.Errorf("unexpected budget error fields: %+v", budgetErr)
+	}
+	if budgetErr.Elapsed < 100*time.Millisecond || budgetErr.Elapsed > budgetErr.Budget {
+		t.Errorf("expect elapsed between first delay and budget, got: %s", budgetErr.Elapsed)
+	}
+	if statusCode != http.StatusServiceUnavailable {
+		t.Errorf("expect last status code %d, got: %d", http.StatusServiceUnavailable, statusCode)
+	}
+	if n := atomic.LoadInt32(&attempts); n != 2 {
+		t.Errorf("expect 2 attempts, got: %d", n)
+	}
+}
+
+func TestWithRetry_budgetLastErr(t *testing.T) {
+	lastErr := errors.New("connection refused")
+	config := NewRetryConfig(WithMaxAttempts(10), WithBaseDelay(50*time.Millisecond), WithRetryBudget(100*time.Millisecond))
+	_, _, _, err := WithRetry(config, func() (int, []byte, http.Header, error) { return 0, nil, nil, lastErr })
+
+	if !IsRetryBudgetExceeded(err) {
+		t.Fatalf("expect retry budget exceeded error, got: %v", err)
+	}
+	if !errors.Is(err, lastErr) {
+		t.Errorf("expect budget error wraps last error, got: %v", err)
+	}
+	if IsRetryBudgetExceeded(lastErr) || IsRetryBudgetExceeded(nil) {
+		t.Errorf("expect other errors not recognized as budget exceeded")
+	}
+}
+
+func TestWithRetry_success(t *testing.T) {
+	var attempts int
+	config := NewRetryConfig(WithMaxAttempts(5), WithBaseDelay(time.Millisecond))
+	statusCode, _, _, err := WithRetry(config, func() (int, []byte, http.Header, error) {
+		if attempts++; attempts < 3 {
+			return http.StatusBadGateway, nil, nil, nil
+		}
+		return http.StatusOK, nil, nil, nil
+	})
+	if err != nil || statusCode != http.StatusOK || attempts != 3 {
+		t.Errorf("unexpected result: status %d, err %v, attempts %d", statusCode, err, attempts)
+	}
+}
+
+func TestWithRetryCtx_cancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var attempts int32
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		cancel()
+	}()
+
+	start := time.Now()
+	statusCode, _, _, err := WithRetryCtx(ctx, NewRetryConfig(WithMaxAttempts(3), WithBaseDelay(10*time.Second)),
+		func() (int, []byte, http.Header, error) {
+			atomic.AddInt32(&attempts, 1)
+			return http.StatusServiceUnavailable, nil, nil, nil
+		})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expect context canceled, got: %v", err)
+	}
+	if statusCode != http.StatusServiceUnavailable {
+		t.Errorf("expect last status code returned, got %d", statusCode)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expect return immediately after cancel, took %s", elapsed)
+	}
+	if n := atomic.LoadInt32(&attempts); n != 1 {
+		t.Errorf("expect 1 attempt, got %d", n)
+	}
+
+	// waiting for rate limiter token is cancelled too
+	lim := NewRetryRateLimiter(1)
+	_ = lim.acquire(context.Background())
+	defer lim.release()
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, _, _, err = WithRetryCtx(ctx, NewRetryConfig(WithBaseDelay(time.Millisecond), WithRetryRateLimiter(lim)),
+		func() (int, []byte, http.Header, error) { return -1, nil, nil, errors.New("fail") })
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expect deadline exceeded waiting for limiter, got: %v", err)
+	}
+}
+
+func Test_calculateBackoff(t *testing.T) {
+	config := NewRetryConfig(WithBaseDelay(100*time.Millisecond), WithMaxDelay(time.Second))
+	for attempt, expected := range []time.Duration{
+		1: 100 * time.Millisecond,
+		2: 200 * time.Millisecond,
+		3: 400 * time.Millisecond,
+		4: 800 * time.Millisecond,
+		5: time.Second,
+		6: time.Second,
+	} {
+		if attempt == 0 {
+			continue
+		}
+		if delay := calculateBackoff(config, attempt); delay != expected {
+			t.Errorf("attempt %d: expect delay %s, got: %s", attempt, expected, delay)
+		}
+	}
+}
+
+func TestWithRetryRateLimiter(t *testing.T) {
+	const limit = 5
+
+	var retrying, maxRetrying int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Attempt") != "1" {
+			n := atomic.AddInt32(&retrying, 1)
+			defer atomic.AddInt32(&retrying, -1)
+			for m := atomic.LoadInt32(&maxRetrying); n > m && !atomic.CompareAndSwapInt32(&maxRetrying, m, n); {
+				m = atomic.LoadInt32(&maxRetrying)
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	config := NewRetryConfig(WithMaxAttempts(3), WithBaseDelay(30*time.Millisecond), WithMaxDelay(30*time.Millisecond),
+		WithRetryRateLimiter(NewRetryRateLimiter(limit)))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var attempt int
+			_, _, _, _ = WithRetry(config, func() (int, []byte, http.Header, error) {
+				attempt++
+				return DoRequestWithOptions(http.MethodGet, server.URL,
+					[]RequestOption{WithSetHeader("X-Attempt", strconv.Itoa(attempt))}, nil)
+			})
+		}()
+	}
+	wg.Wait()
+
+	if n := atomic.LoadInt32(&maxRetrying); n == 0 || n > limit {
+		t.Errorf("expect at most %d simultaneous retries, got: %d", limit, n)
+	}
+}
+
+func TestNewRetryRateLimiter_invalid(t *testing.T) {
+	for _, n := range []int{-1, 0} {
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			_, _, _, _ = WithRetry(NewRetryConfig(WithBaseDelay(time.Millisecond), WithRetryRateLimiter(NewRetryRateLimiter(n))),
+				func() (int, []byte, http.Header, error) { return -1, nil, nil, errors.New("fail") })
+		}()
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatalf("retry with limiter of %d blocked", n)
+		}
+	}
+}
+
+func TestWithRetryOnStatus(t *testing.T) {
+	for _, testcase := range []struct {
+		opts     []RetryOption
+		expected []int
+	}{
+		{nil, DefaultRetryConfig.RetryOnStatus},
+		{[]RetryOption{WithRetryOnStatus(http.StatusConflict)}, []int{http.StatusConflict}},
+		{[]RetryOption{WithRetryOnStatus()}, []int{}},
+		{[]RetryOption{WithAddRetryOnStatus(http.StatusConflict, http.StatusBadGateway)},
+			append(append([]int(nil), DefaultRetryConfig.RetryOnStatus...), http.StatusConflict)},
+		{[]RetryOption{WithRemoveRetryOnStatus(http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusTeapot)},
+			[]int{http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout}},
+		{[]RetryOption{WithRetryOnStatus(http.StatusConflict), WithAddRetryOnStatus(http.StatusLocked), WithRemoveRetryOnStatus(http.StatusConflict)},
+			[]int{http.StatusLocked}},
+	} {
+		if got := NewRetryConfig(testcase.opts...).RetryOnStatus; fmt.Sprint(got) != fmt.Sprint(testcase.expected) {
+			t.Errorf("unexpected retry on status: %v, expect: %v", got, testcase.expected)
+		}
+	}
+
+	if len(DefaultRetryConfig.RetryOnStatus) != 5 || DefaultRetryConfig.RetryOnStatus[0] != http.StatusTooManyRequests {
+		t.Errorf("default retry config should not be modified: %v", DefaultRetryConfig.RetryOnStatus)
+	}
+}
+
+func TestDoRequestWithRetry_bodyReplay(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		bodies []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		defer mu.Unlock()
+		if bodies = append(bodies, string(data)); len(bodies)%3 != 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	file, err := os.CreateTemp(t.TempDir(), "body")
+	if err != nil {
+		t.Fatalf("create temp file fail: %s", err)
+	}
+	defer file.Close()
+	_, _ = file.WriteString("skipped file body")
+	_, _ = file.Seek(int64(len("skipped ")), io.SeekStart)
+
+	config := NewRetryConfig(WithMaxAttempts(3), WithBaseDelay(time.Millisecond))
+	for _, testcase := range []struct {
+		body     io.Reader
+		expected string
+	}{
+		{bytes.NewBufferString("buffer body"), "buffer body"}, // can be read only once
+		{strings.NewReader("reader body"), "reader body"},
+		{file, "file body"},
+	} {
+		mu.Lock()
+		bodies = nil
+		mu.Unlock()
+
+		statusCode, _, _, err := DoRequestWithRetry(config, http.MethodPost, server.URL, nil, testcase.body)
+		if err != nil || statusCode != http.StatusOK {
+			t.Errorf("expect success after retries, got %d, err: %v", statusCode, err)
+		}
+		mu.Lock()
+		if expected := []string{testcase.expected, testcase.expected, testcase.expected}; fmt.Sprint(bodies) != fmt.Sprint(expected) {
+			t.Errorf("expect body %q sent on every attempt, got: %q", testcase.expected, bodies)
+		}
+		mu.Unlock()
+	}
+}
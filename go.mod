@@ -1,6 +1,6 @@
 module github.com/tr1v3r/pkg
 
-go 1.18
+go 1.24
 
 require (
 	github.com/gin-gonic/gin v1.8.1
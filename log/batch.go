@@ -0,0 +1,124 @@
+package log
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var _ Handler = (*batchHandler)(nil)
+
+// NewBatchHandler wrap inner handler, aggregating identical messages with same level within window,
+// first occurrence of message in window is output immediately, if it appears N>1 times in window,
+// "[N times] message" is output at the end of window, messages output after Close pass through to inner handler
+func NewBatchHandler(inner Handler, window time.Duration) Handler {
+	h := &batchHandler{Handler: inner, ticker: time.NewTicker(window), done: make(chan struct{}), stopped: make(chan struct{})}
+	go h.serve()
+	return h
+}
+
+type batchHandler struct {
+	Handler
+
+	entries sync.Map // batchKey -> *batchEntry
+
+	mu     sync.RWMutex // guard closed, held by Output while storing entry so Close flushes all stored
+	closed bool
+
+	ticker    *time.Ticker
+	done      chan struct{} // closed by Close to stop serve
+	stopped   chan struct{} // closed by serve after final flush
+	closeOnce sync.Once
+}
+
+type batchKey struct {
+	level Level
+	msg   string
+}
+
+type batchEntry struct {
+	mu    sync.Mutex
+	ctx   context.Context // context of the first message
+	count int             // occurrences in window, including the first one
+	done  bool            // already output, new message should start another entry
+}
+
+func (h *batchHandler) Output(level Level, ctx context.Context, format string, v ...any) {
+	key := batchKey{level: level, msg: fmt.Sprintf(format, v...)}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if h.closed {
+		h.Handler.Output(level, ctx, "%s", key.msg)
+		return
+	}
+	for {
+		value, loaded := h.entries.LoadOrStore(key, &batchEntry{ctx: ctx, count: 1})
+		if !loaded { // first occurrence passes through
+			h.Handler.Output(level, ctx, "%s", key.msg)
+			return
+		}
+		entry := value.(*batchEntry)
+
+		entry.mu.Lock()
+		if entry.done {
+			entry.mu.Unlock()
+			continue
+		}
+		entry.count++
+		entry.mu.Unlock()
+		return
+	}
+}
+
+// Flush output aggregated messages and flush inner handler
+func (h *batchHandler) Flush() {
+	h.flush()
+	h.Handler.Flush()
+}
+
+// Close stop window ticker, output aggregated messages and close inner handler
+func (h *batchHandler) Close() {
+	h.closeOnce.Do(func() {
+		h.mu.Lock()
+		h.closed = true
+		h.mu.Unlock()
+
+		h.ticker.Stop()
+		close(h.done)
+		<-h.stopped
+		h.Handler.Close()
+	})
+}
+
+func (h *batchHandler) serve() {
+	defer close(h.stopped)
+	for {
+		select {
+		case <-h.ticker.C:
+			h.flush()
+		case <-h.done:
+			h.flush()
+			return
+		}
+	}
+}
+
+func (h *batchHandler) flush() {
+	h.entries.Range(func(k, v any) bool {
+		h.entries.Delete(k)
+
+		entry := v.(*batchEntry)
+		entry.mu.Lock()
+		entry.done = true
+		count := entry.count
+		entry.mu.Unlock()
+
+		if count > 1 {
+			key := k.(batchKey)
+			h.Handler.Output(key.level, entry.ctx, "[%d times] %s", count, key.msg)
+		}
+		return true
+	})
+}
@@ -0,0 +1,142 @@
+package log
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordHandler record formatted messages output to it
+type recordHandler struct {
+	Handler
+
+	mu   sync.Mutex
+	msgs []string
+}
+
+func newRecordHandler() *recordHandler {
+	inner := NewStreamHandler(DebugLevel)
+	inner.SetOutput(io.Discard)
+	return &recordHandler{Handler: inner}
+}
+
+func (h *recordHandler) Output(level Level, _ context.Context, format string, v ...any) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.msgs = append(h.msgs, level.String()+" "+fmt.Sprintf(format, v...))
+}
+
+func (h *recordHandler) messages() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]string(nil), h.msgs...)
+}
+
+func TestNewBatchHandler(t *testing.T) {
+	inner := newRecordHandler()
+	handler := NewBatchHandler(inner, 200*time.Millisecond)
+
+	for i := 0; i < 100; i++ {
+		handler.Output(InfoLevel, context.Background(), "duplicated %d", 1)
+		time.Sleep(100 * time.Microsecond)
+	}
+	handler.Output(WarnLevel, context.Background(), "duplicated %d", 1)
+	handler.Output(InfoLevel, context.Background(), "single")
+
+	if !waitFor(time.Second, func() bool { return len(inner.messages()) > 3 }) {
+		t.Fatal("aggregated messages not output at the end of window")
+	}
+	handler.Close()
+
+	msgs := inner.messages()
+	sort.Strings(msgs)
+	expected := []string{InfoLevel.String() + " duplicated 1", InfoLevel.String() + " [100 times] duplicated 1",
+		InfoLevel.String() + " single", WarnLevel.String() + " duplicated 1"}
+	sort.Strings(expected)
+	if fmt.Sprint(msgs) != fmt.Sprint(expected) {
+		t.Errorf("expect messages %q, got %q", expected, msgs)
+	}
+}
+
+func TestBatchHandler_nextWindow(t *testing.T) {
+	inner := newRecordHandler()
+	handler := NewBatchHandler(inner, time.Hour)
+
+	handler.Output(InfoLevel, context.Background(), "message")
+	handler.Output(InfoLevel, context.Background(), "message")
+	handler.Flush()
+	handler.Output(InfoLevel, context.Background(), "message")
+	handler.Close()
+
+	msgs := inner.messages()
+	expected := []string{InfoLevel.String() + " message", InfoLevel.String() + " [2 times] message", InfoLevel.String() + " message"}
+	if fmt.Sprint(msgs) != fmt.Sprint(expected) {
+		t.Errorf("expect messages %q, got %q", expected, msgs)
+	}
+}
+
+func TestBatchHandler_passThrough(t *testing.T) {
+	inner := newRecordHandler()
+	handler := NewBatchHandler(inner, time.Hour)
+	defer handler.Close()
+
+	for _, msg := range []string{"a", "b", "a", "c", "a", "b"} {
+		handler.Output(InfoLevel, context.Background(), "%s", msg)
+	}
+
+	// first occurrences output immediately in order
+	expected := []string{InfoLevel.String() + " a", InfoLevel.String() + " b", InfoLevel.String() + " c"}
+	if msgs := inner.messages(); fmt.Sprint(msgs) != fmt.Sprint(expected) {
+		t.Errorf("expect messages %q before window end, got %q", expected, msgs)
+	}
+
+	handler.Flush()
+	msgs := inner.messages()
+	if len(msgs) != 5 || fmt.Sprint(msgs[:3]) != fmt.Sprint(expected) {
+		t.Fatalf("unexpected messages after window end: %q", msgs)
+	}
+	repeated := msgs[3:]
+	sort.Strings(repeated)
+	if fmt.Sprint(repeated) != fmt.Sprint([]string{InfoLevel.String() + " [2 times] b", InfoLevel.String() + " [3 times] a"}) {
+		t.Errorf("unexpected aggregated messages: %q", repeated)
+	}
+}
+
+func TestBatchHandler_Close(t *testing.T) {
+	inner := newRecordHandler()
+	handler := NewBatchHandler(inner, time.Millisecond)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				handler.Output(InfoLevel, context.Background(), "message")
+			}
+		}()
+	}
+	time.Sleep(time.Millisecond)
+	handler.Close()
+	wg.Wait()
+	handler.Close()
+
+	// every message is counted, either aggregated before Close or passed through after it,
+	// "[N times]" includes first occurrence already output
+	var total int
+	for _, msg := range inner.messages() {
+		var n int
+		if _, err := fmt.Sscanf(msg, InfoLevel.String()+" [%d times] message", &n); err != nil {
+			total++
+		} else {
+			total += n - 1
+		}
+	}
+	if total != 8000 {
+		t.Errorf("expect 8000 messages output, got %d", total)
+	}
+}
@@ -0,0 +1,121 @@
+package log
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// closeRotated close rotated log file, and compress it if required
+func (f *FileHandler) closeRotated(file *os.File) {
+	_ = file.Close()
+	if !f.compressRotated {
+		return
+	}
+	if err := compressFile(file.Name()); err != nil {
+		fmt.Fprintf(os.Stderr, "compress rotated log file fail: %s\n", err)
+	}
+}
+
+// compressLeftovers compress log files of handler in dir except ones being written
+func (f *FileHandler) compressLeftovers() {
+	current := map[string]bool{f.FileName(): true}
+	if f.levelSplit {
+		for level := TraceLevel; level <= PanicLevel; level++ {
+			current[f.levelFileName(level)] = true
+		}
+	}
+	if f.mirrorDir == f.Dir { // mirror files are in log dir too
+		for name := range current {
+			current[strings.TrimSuffix(name, "log")+"mirror.log"] = true
+		}
+	}
+
+	matches, err := filepath.Glob(filepath.Join(f.Dir, f.filePrefix+"*log"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "find rotated log files fail: %s\n", err)
+		return
+	}
+	for _, name := range matches {
+		if current[name] || !f.ownFile(filepath.Base(name)) {
+			continue
+		}
+		if err := compressFile(name); err != nil {
+			fmt.Fprintf(os.Stderr, "compress rotated log file fail: %s\n", err)
+		}
+	}
+}
+
+// ownFile report whether base is name of log file written by handler:
+// prefix + [level.] + interval time + [mirror.] + log, files of other handlers in dir are excluded
+func (f *FileHandler) ownFile(base string) bool {
+	if !strings.HasPrefix(base, f.filePrefix) || !strings.HasSuffix(base, "log") {
+		return false
+	}
+	rest := strings.TrimSuffix(strings.TrimPrefix(base, f.filePrefix), "log")
+	if f.mirrorDir == f.Dir {
+		rest = strings.TrimSuffix(rest, "mirror.")
+	}
+	if f.levelSplit {
+		for level := TraceLevel; level <= PanicLevel; level++ {
+			if levelRest := strings.TrimPrefix(rest, strings.ToLower(level.String())+"."); levelRest != rest {
+				rest = levelRest
+				break
+			}
+		}
+	}
+
+	layout := f.intervalLayout()
+	if layout == "" {
+		return rest == ""
+	}
+	_, err := time.Parse(layout, rest)
+	return err == nil
+}
+
+// levelFileName return name of current log file of level when split by level
+func (f *FileHandler) levelFileName(level Level) string {
+	h := FileHandler{Dir: f.Dir, intervalLevel: f.intervalLevel, filePrefix: f.filePrefix + strings.ToLower(level.String()) + "."}
+	return h.FileName()
+}
+
+// compressFile gzip file to name.gz and remove it, existing name.gz is not overwritten
+func compressFile(name string) error {
+	if _, err := os.Stat(name + ".gz"); err == nil {
+		return fmt.Errorf("compressed file %s.gz already exists", name)
+	}
+
+	src, err := os.Open(name)
+	if err != nil {
+		return fmt.Errorf("open %s fail: %w", name, err)
+	}
+	defer src.Close()
+
+	tmpName := name + ".gz.tmp"
+	dst, err := os.OpenFile(tmpName, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return fmt.Errorf("create %s fail: %w", tmpName, err)
+	}
+	defer os.Remove(tmpName) // no-op after renamed
+
+	zw := gzip.NewWriter(dst)
+	zw.Name = filepath.Base(name)
+	if _, err = io.Copy(zw, src); err == nil {
+		err = zw.Close()
+	}
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("compress %s fail: %w", name, err)
+	}
+
+	if err := os.Rename(tmpName, name+".gz"); err != nil {
+		return fmt.Errorf("rename %s fail: %w", tmpName, err)
+	}
+	return os.Remove(name)
+}
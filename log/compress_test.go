@@ -0,0 +1,132 @@
+package log
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// readGzip return decompressed content of gzip file, or error message
+func readGzip(name string) string {
+	file, err := os.Open(name)
+	if err != nil {
+		return err.Error()
+	}
+	defer file.Close()
+	zr, err := gzip.NewReader(file)
+	if err != nil {
+		return err.Error()
+	}
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		return err.Error()
+	}
+	return string(data)
+}
+
+func TestFileHandler_CompressOnStartup(t *testing.T) {
+	dir := t.TempDir()
+	probe, _ := NewFileHandler(TraceLevel, dir, FileHandlerLogFilePrefix("app"))
+	current := probe.FileName()
+
+	rotated := map[string]string{
+		filepath.Join(dir, "app.2024-01-01T_01.log"): "first\n",
+		filepath.Join(dir, "app.2024-01-01T_02.log"): "second\n",
+	}
+	for name, content := range rotated {
+		if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatalf("create rotated file fail: %s", err)
+		}
+	}
+	for _, name := range []string{current, filepath.Join(dir, "other.2024-01-01T_01.log")} {
+		if err := os.WriteFile(name, []byte("untouched\n"), 0644); err != nil {
+			t.Fatalf("create file fail: %s", err)
+		}
+	}
+
+	if _, err := NewFileHandler(TraceLevel, dir, FileHandlerLogFilePrefix("app"), FileHandlerCompressOnStartup()); err != nil {
+		t.Fatalf("create new file handler fail: %s", err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if _, err := os.Stat(filepath.Join(dir, "app.2024-01-01T_01.log.gz")); err == nil {
+		t.Errorf("expect no compression without FileHandlerCompressRotated")
+	}
+
+	_, err := NewFileHandler(TraceLevel, dir, FileHandlerLogFilePrefix("app"), FileHandlerCompressRotated(), FileHandlerCompressOnStartup())
+	if err != nil {
+		t.Fatalf("create new file handler fail: %s", err)
+	}
+	for name, content := range rotated {
+		if !waitFor(time.Second, func() bool { _, err := os.Stat(name); return os.IsNotExist(err) }) {
+			t.Errorf("expect %s compressed and removed", name)
+		}
+		if got := readGzip(name + ".gz"); got != content {
+			t.Errorf("unexpected content of %s.gz: %q", name, got)
+		}
+	}
+	for _, name := range []string{current, filepath.Join(dir, "other.2024-01-01T_01.log")} {
+		if data, _ := os.ReadFile(name); string(data) != "untouched\n" {
+			t.Errorf("expect %s untouched, got: %q", name, data)
+		}
+	}
+}
+
+func TestFileHandler_CompressOnStartup_foreignFiles(t *testing.T) {
+	dir := t.TempDir()
+	other, err := NewFileHandler(TraceLevel, dir, FileHandlerLogFilePrefix("app"))
+	if err != nil {
+		t.Fatalf("create file handler fail: %s", err)
+	}
+	other.Output(InfoLevel, nil, "other handler")
+	other.Flush()
+	otherCurrent := other.FileName()
+
+	own := filepath.Join(dir, "2024-01-01T_01.log")
+	foreign := []string{filepath.Join(dir, "foo.log"), filepath.Join(dir, "app.2024-01-01T_01.log"), filepath.Join(dir, "2024-01-01.log")}
+	for _, name := range append([]string{own}, foreign...) {
+		if err := os.WriteFile(name, []byte("content\n"), 0644); err != nil {
+			t.Fatalf("create file fail: %s", err)
+		}
+	}
+
+	if _, err := NewFileHandler(TraceLevel, dir, FileHandlerCompressRotated(), FileHandlerCompressOnStartup()); err != nil {
+		t.Fatalf("create file handler fail: %s", err)
+	}
+	if !waitFor(time.Second, func() bool { _, err := os.Stat(own); return os.IsNotExist(err) }) {
+		t.Errorf("expect %s compressed and removed", own)
+	}
+	time.Sleep(50 * time.Millisecond)
+	for _, name := range append(foreign, otherCurrent) {
+		if _, err := os.Stat(name); err != nil {
+			t.Errorf("expect file of other handler %s untouched, got: %v", name, err)
+		}
+		if _, err := os.Stat(name + ".gz"); err == nil {
+			t.Errorf("expect file of other handler %s not compressed", name)
+		}
+	}
+}
+
+func TestFileHandler_CompressRotated(t *testing.T) {
+	handler, err := NewFileHandler(TraceLevel, t.TempDir(), FileHandlerInterval(0), FileHandlerCompressRotated())
+	if err != nil {
+		t.Fatalf("create new file handler fail: %s", err)
+	}
+	handler.writeMsg([]byte("before rotation\n"))
+	rotated := handler.FileName()
+
+	handler.intervalLevel = IntervalHour
+	handler.writeMsg([]byte("after rotation\n"))
+
+	if !waitFor(time.Second, func() bool { _, err := os.Stat(rotated); return os.IsNotExist(err) }) {
+		t.Fatalf("expect rotated file %s compressed and removed", rotated)
+	}
+	if got := readGzip(rotated + ".gz"); got != "before rotation\n" {
+		t.Errorf("unexpected content of rotated file: %q", got)
+	}
+	if data, _ := os.ReadFile(handler.FileName()); string(data) != "after rotation\n" {
+		t.Errorf("unexpected content of current file: %q", data)
+	}
+}
@@ -0,0 +1,8 @@
+//go:build !(linux || darwin || freebsd)
+
+package log
+
+import "errors"
+
+// freeDiskBytes is not supported on this platform, disk space check is skipped
+func freeDiskBytes(string) (uint64, error) { return 0, errors.New("disk space check not supported") }
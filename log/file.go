@@ -8,7 +8,9 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"golang.org/x/time/rate"
@@ -32,9 +34,15 @@ func NewFileHandler(level Level, dir string, opts ...FileHandlerOption) (f *File
 	}
 
 	defer func() {
+		if f == nil {
+			return
+		}
 		for _, opt := range opts {
 			f = opt(f)
 		}
+		if f.compressRotated && f.compressOnStartup {
+			go f.compressLeftovers()
+		}
 	}()
 
 	return &FileHandler{
@@ -49,6 +57,9 @@ func NewFileHandler(level Level, dir string, opts ...FileHandlerOption) (f *File
 		closed: make(chan struct{}),
 
 		limiter: rate.NewLimiter(100, 1000),
+
+		diskFree: freeDiskBytes,
+		fallback: os.Stderr,
 	}, nil
 }
 
@@ -73,6 +84,14 @@ var (
 		}
 	}
 
+	// FileHandlerIntervalLevel set file interval level, e.g. parsed by ParseIntervalLevel
+	FileHandlerIntervalLevel = func(level IntervalLevel) FileHandlerOption {
+		return func(handler *FileHandler) *FileHandler {
+			handler.intervalLevel = level
+			return handler
+		}
+	}
+
 	// FileHandlerFormatter set file formatter
 	FileHandlerFormatter = func(f Formatter) FileHandlerOption {
 		return func(handler *FileHandler) *FileHandler {
@@ -87,6 +106,55 @@ var (
 			return handler
 		}
 	}
+	// FileHandlerMinFreeDisk stop writing when free disk space of log dir falls below mb megabytes,
+	// resume when space becomes available, messages during stop are dropped
+	FileHandlerMinFreeDisk = func(mb int64) FileHandlerOption {
+		return func(handler *FileHandler) *FileHandler {
+			handler.minFreeDisk = uint64(mb) << 20
+			return handler
+		}
+	}
+	// FileHandlerMirrorPath write every message to a mirror log file in dir as well, rotated along with log file,
+	// mirror file in log dir is named like 2006-01-02T_15.mirror.log, failure of mirror is reported to stderr only
+	FileHandlerMirrorPath = func(dir string) FileHandlerOption {
+		return func(handler *FileHandler) *FileHandler {
+			if abs, err := filepath.Abs(dir); err == nil {
+				dir = abs
+			}
+			handler.mirrorDir = dir
+			return handler
+		}
+	}
+	// FileHandlerLevelSplit write messages of each level to separate log files like info.2006-01-02T_15.log,
+	// all files share interval and other settings of handler
+	FileHandlerLevelSplit = func() FileHandlerOption {
+		return func(handler *FileHandler) *FileHandler {
+			handler.levelSplit = true
+			return handler
+		}
+	}
+	// FileHandlerCompressRotated gzip log file in background after rotated, like 2006-01-02T_15.log.gz
+	FileHandlerCompressRotated = func() FileHandlerOption {
+		return func(handler *FileHandler) *FileHandler {
+			handler.compressRotated = true
+			return handler
+		}
+	}
+	// FileHandlerCompressOnStartup gzip rotated log files left uncompressed in log dir in background when handler created,
+	// e.g. by crashed process, works with FileHandlerCompressRotated only
+	FileHandlerCompressOnStartup = func() FileHandlerOption {
+		return func(handler *FileHandler) *FileHandler {
+			handler.compressOnStartup = true
+			return handler
+		}
+	}
+	// FileHandlerDrainTimeout set max duration Close waits for queued messages written, zero means no limit
+	FileHandlerDrainTimeout = func(timeout time.Duration) FileHandlerOption {
+		return func(handler *FileHandler) *FileHandler {
+			handler.drainTimeout = timeout
+			return handler
+		}
+	}
 )
 
 // IntervalLevel set interval level
@@ -103,6 +171,25 @@ const (
 	IntervalDay IntervalLevel = "day"
 )
 
+// ParseIntervalLevel parse interval level from config, case-insensitive,
+// accepts none, minute, hour, day and their adverbs like hourly
+func ParseIntervalLevel(s string) (IntervalLevel, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "none":
+		return IntervalNone, nil
+	case "minute", "minutely":
+		return IntervalMinute, nil
+	case "hour", "hourly":
+		return IntervalHour, nil
+	case "day", "daily":
+		return IntervalDay, nil
+	default:
+		return "", fmt.Errorf("unknown interval level: %q", s)
+	}
+}
+
+func (l IntervalLevel) String() string { return string(l) }
+
 // FileHandler file handler
 type FileHandler struct {
 	Formatter
@@ -116,40 +203,126 @@ type FileHandler struct {
 	level Level
 	ch    chan []byte
 
-	mu  sync.RWMutex
-	out *os.File
+	mu    sync.RWMutex
+	out   *os.File
+	extra io.Writer // extra outputs besides log file
+
+	mirrorDir string   // dir of mirror log file, empty means no mirror
+	mirror    *os.File // mirror of out, nil if mirror not set or failed to open
+
+	levelSplit bool                   // write each level to separate file
+	splitMu    sync.Mutex             // guard splits
+	splits     map[Level]*FileHandler // handlers writing file of each level, created on first message of level
+	extraMu    sync.Mutex             // serialize writes of level handlers to extra outputs
+
+	compressRotated   bool // gzip log file after rotated
+	compressOnStartup bool // gzip rotated log files left in dir when created
+
+	bytesWritten atomic.Int64
+	rotations    int       // times log file switched after first opened, guarded by mu
+	reopens      int       // times same log file reopened after removed or write failed, guarded by mu
+	openedAt     time.Time // when current log file opened, guarded by mu
+	fallback     io.Writer // output of messages failed writing to log file
+
+	reopenBackoff time.Duration // current backoff of reopening, used by writer only
+	reopenAfter   time.Time     // no reopen before it while writes keep failing, used by writer only
 
-	closeOnce sync.Once
-	closed    chan struct{}
+	closeOnce    sync.Once
+	closed       chan struct{}
+	drainTimeout time.Duration
+	aborted      atomic.Bool // drain timeout exceeded when closing, drop messages left
 
 	once    sync.Once
 	limiter *rate.Limiter
+
+	minFreeDisk   uint64 // min free disk bytes, zero means no check
+	diskMu        sync.Mutex
+	diskLow       bool // free disk below minFreeDisk at last check
+	diskCheckedAt time.Time
+	diskFree      func(dir string) (uint64, error)
 }
 
 func (f *FileHandler) SetLevel(level Level)        { f.level = level }
 func (f *FileHandler) allowLevel(level Level) bool { return level >= f.level }
 
-func (f *FileHandler) SetOutput(out io.Writer)      { /* do nothing */ }
-func (f *FileHandler) RegisterOutput(out io.Writer) { /* do nothing */ }
+// SetOutput set extra output besides log file, replacing outputs registered before
+func (f *FileHandler) SetOutput(out io.Writer) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.extra = out
+}
+
+// RegisterOutput register extra output besides log file
+func (f *FileHandler) RegisterOutput(out io.Writer) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.extra == nil {
+		f.extra = out
+	} else {
+		f.extra = io.MultiWriter(f.extra, out)
+	}
+}
+
+// AddOutput alias of RegisterOutput
+func (f *FileHandler) AddOutput(out io.Writer) { f.RegisterOutput(out) }
+
+// AddOutputs register extra outputs besides log file
+func (f *FileHandler) AddOutputs(outs ...io.Writer) {
+	for _, out := range outs {
+		f.RegisterOutput(out)
+	}
+}
 
 func (f *FileHandler) Output(level Level, ctx context.Context, format string, v ...any) {
+	if f.levelSplit {
+		if f.allowLevel(level) {
+			f.splitHandler(level).Output(level, ctx, format, v...)
+		}
+		return
+	}
+
 	f.once.Do(func() {
 		_ = f.refreshWriter()
 		go f.serve()
 	})
 	if f.allowLevel(level) {
-		f.ch <- []byte(fmt.Sprintf(f.Format(level, ctx, format), v...))
+		f.ch <- []byte(f.Format(level, ctx, fmt.Sprintf(format, v...)))
 	}
 }
 
 func (f *FileHandler) Write(p []byte) (int, error) {
+	n, err := f.writeFile(p)
+	if err == nil {
+		err = f.writeExtra(p)
+	}
+	return n, err
+}
+
+func (f *FileHandler) writeFile(p []byte) (int, error) {
 	if err := f.refreshWriter(); err != nil {
 		return 0, fmt.Errorf("refresh writer fail: %w", err)
 	}
 
 	f.mu.RLock()
 	defer f.mu.RUnlock()
-	return f.out.Write(p)
+	n, err := f.out.Write(p)
+	f.bytesWritten.Add(int64(n))
+	if f.mirror != nil {
+		if _, mErr := f.mirror.Write(p); mErr != nil {
+			fmt.Fprintf(os.Stderr, "write mirror log file %s fail: %s\n", f.mirror.Name(), mErr)
+		}
+	}
+	return n, err
+}
+
+func (f *FileHandler) writeExtra(p []byte) error {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	if f.extra == nil {
+		return nil
+	}
+	_, err := f.extra.Write(p)
+	return err
 }
 
 func (f *FileHandler) FileName() string {
@@ -158,18 +331,24 @@ func (f *FileHandler) FileName() string {
 
 	fileName.WriteString(f.filePrefix)
 
-	now := time.Now()
+	fileName.WriteString(time.Now().Format(f.intervalLayout()))
+	fileName.WriteString("log")
+
+	return fileName.String()
+}
+
+// intervalLayout return time layout in log file name of interval level
+func (f *FileHandler) intervalLayout() string {
 	switch f.intervalLevel {
 	case IntervalMinute:
-		fileName.WriteString(now.Format("2006-01-02T_15_04."))
+		return "2006-01-02T_15_04."
 	case IntervalHour:
-		fileName.WriteString(now.Format("2006-01-02T_15."))
+		return "2006-01-02T_15."
 	case IntervalDay:
-		fileName.WriteString(now.Format("2006-01-02."))
+		return "2006-01-02."
+	default:
+		return ""
 	}
-	fileName.WriteString("log")
-
-	return fileName.String()
 }
 
 func (f *FileHandler) file() (*os.File, error) {
@@ -181,6 +360,28 @@ func (f *FileHandler) file() (*os.File, error) {
 	return curFile, nil
 }
 
+// mirrorFile open mirror of log file name, return nil if mirror not set
+func (f *FileHandler) mirrorFile(name string) (*os.File, error) {
+	if f.mirrorDir == "" {
+		return nil, nil
+	}
+
+	base := filepath.Base(name)
+	if f.mirrorDir == f.Dir {
+		base = strings.TrimSuffix(base, "log") + "mirror.log"
+	}
+	if err := os.MkdirAll(f.mirrorDir, 0755); err != nil {
+		return nil, fmt.Errorf("create mirror dir fail: %w", err)
+	}
+
+	mirrorFileName := filepath.Join(f.mirrorDir, base)
+	mirror, err := os.OpenFile(mirrorFileName, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("open mirror log file %s fail: %w", mirrorFileName, err)
+	}
+	return mirror, nil
+}
+
 // checkOutput return f.out if valid, else return nil
 func (f *FileHandler) needRefreshWriter() bool {
 	if !f.limiter.Allow() {
@@ -189,40 +390,110 @@ func (f *FileHandler) needRefreshWriter() bool {
 
 	f.mu.RLock()
 	defer f.mu.RUnlock()
-	return f.out == nil || f.out.Name() != f.FileName()
+	return f.out == nil || f.out.Name() != f.FileName() || removed(f.out)
+}
+
+// removed report whether file is removed or replaced by others like logrotate
+func removed(file *os.File) bool {
+	info, err := os.Stat(file.Name())
+	if err != nil {
+		return true
+	}
+	opened, err := file.Stat()
+	return err == nil && !os.SameFile(info, opened)
 }
 
 func (f *FileHandler) refreshWriter() error {
 	if !f.needRefreshWriter() {
 		return nil
 	}
+	return f.reopen()
+}
 
-	// create new file output writer
+// reopen open current log file and replace output
+func (f *FileHandler) reopen() error {
 	output, err := f.file()
 	if err != nil {
 		return err
 	}
+	mirror, err := f.mirrorFile(output.Name())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s, write log file only\n", err)
+	}
 
 	f.mu.Lock()
+	if m := f.mirror; m != nil {
+		go m.Close()
+	}
+	f.mirror = mirror
 	if o := f.out; o != nil {
-		go o.Close()
+		if o.Name() == output.Name() {
+			go o.Close()
+			f.reopens++
+		} else {
+			go f.closeRotated(o)
+			f.rotations++
+		}
 	}
-	f.out = output
+	f.out, f.openedAt = output, time.Now()
 	f.mu.Unlock()
 
 	return nil
 }
 
+// FileHandlerStats file handler statistics
+type FileHandlerStats struct {
+	BytesWritten    int64     // bytes written to log files
+	RotationCount   int       // times log file rotated
+	ReopenCount     int       // times log file reopened after removed or write failed
+	CurrentFilePath string    // path of current log file, empty if not opened yet
+	OpenedAt        time.Time // when current log file opened
+}
+
+// GetStats return statistics of handler, counts of all level files are summed when split by level
+func (f *FileHandler) GetStats() FileHandlerStats {
+	if f.levelSplit {
+		var stats FileHandlerStats
+		for _, h := range f.splitHandlers() {
+			s := h.GetStats()
+			stats.BytesWritten += s.BytesWritten
+			stats.RotationCount += s.RotationCount
+			stats.ReopenCount += s.ReopenCount
+		}
+		return stats
+	}
+
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	stats := FileHandlerStats{
+		BytesWritten:  f.bytesWritten.Load(),
+		RotationCount: f.rotations,
+		ReopenCount:   f.reopens,
+		OpenedAt:      f.openedAt,
+	}
+	if f.out != nil {
+		stats.CurrentFilePath = f.out.Name()
+	}
+	return stats
+}
+
 func (f *FileHandler) Flush() {
+	if f.levelSplit {
+		for _, h := range f.splitHandlers() {
+			h.Flush()
+		}
+		return
+	}
+
 	runtime.Gosched()
 	for i := cap(f.ch); i > 0; i-- { // max try times less than capacity of ch
 		select {
 		case msg, ok := <-f.ch:
-			if !ok {
-				f.close() // in case serve goroutine not running
+			if !ok { // closed, serve goroutine started by Close closes files
 				return
 			}
-			_, _ = f.Write(msg)
+			f.writeMsg(msg)
 		default:
 			f.flush()
 			return
@@ -234,19 +505,186 @@ func (f *FileHandler) flush() {
 	f.mu.RLock()
 	defer f.mu.RUnlock()
 	_ = f.out.Sync()
+	if f.mirror != nil {
+		_ = f.mirror.Sync()
+	}
 }
 
+// Close stop accepting messages, wait for queued messages written and close log files,
+// when drain timeout exceeded, messages not written yet are dropped,
+// and log files are closed once the message being written returns
 func (f *FileHandler) Close() {
+	if f.levelSplit {
+		for _, h := range f.splitHandlers() {
+			h.Close()
+		}
+		return
+	}
+
 	close(f.ch)
-	f.Flush()
-	<-f.closed
+	f.once.Do(func() { go f.serve() }) // in case serve goroutine not running
+
+	var timeout <-chan time.Time
+	if f.drainTimeout > 0 {
+		timer := time.NewTimer(f.drainTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	select {
+	case <-f.closed:
+	case <-timeout:
+		f.aborted.Store(true)
+		fmt.Fprintf(os.Stderr, "file handler drain timeout after %s, %d messages dropped, log files still open until writing message returns\n",
+			f.drainTimeout, len(f.ch))
+	}
+}
+
+// closeFiles sync and close log file and its mirror
+func (f *FileHandler) closeFiles() {
+	f.flush()
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.out != nil {
+		_ = f.out.Close()
+	}
+	if f.mirror != nil {
+		_ = f.mirror.Close()
+	}
+}
+
+// splitHandler return handler writing file of level, created if not exists
+func (f *FileHandler) splitHandler(level Level) *FileHandler {
+	f.splitMu.Lock()
+	defer f.splitMu.Unlock()
+	if h, ok := f.splits[level]; ok {
+		return h
+	}
+
+	h := &FileHandler{
+		Formatter: f.Formatter,
+
+		Dir: f.Dir,
+
+		intervalLevel: f.intervalLevel,
+		filePrefix:    f.filePrefix + strings.ToLower(level.String()) + ".",
+
+		level: level,
+		ch:    make(chan []byte, cap(f.ch)),
+		extra: splitExtra{f},
+
+		mirrorDir: f.mirrorDir,
+
+		compressRotated: f.compressRotated,
+
+		closed:       make(chan struct{}),
+		drainTimeout: f.drainTimeout,
+
+		limiter: rate.NewLimiter(100, 1000),
+
+		minFreeDisk: f.minFreeDisk,
+		diskFree:    f.diskFree,
+		fallback:    f.fallback,
+	}
+	if f.splits == nil {
+		f.splits = make(map[Level]*FileHandler)
+	}
+	f.splits[level] = h
+	return h
+}
+
+// splitExtra extra output of level handlers, writing to extra outputs of parent handler one by one
+type splitExtra struct{ parent *FileHandler }
+
+func (e splitExtra) Write(p []byte) (int, error) {
+	e.parent.extraMu.Lock()
+	defer e.parent.extraMu.Unlock()
+	return len(p), e.parent.writeExtra(p)
+}
+
+// splitHandlers return handlers of all levels written
+func (f *FileHandler) splitHandlers() []*FileHandler {
+	f.splitMu.Lock()
+	defer f.splitMu.Unlock()
+	handlers := make([]*FileHandler, 0, len(f.splits))
+	for _, h := range f.splits {
+		handlers = append(handlers, h)
+	}
+	return handlers
 }
 
 func (f *FileHandler) close() { f.closeOnce.Do(func() { close(f.closed) }) }
 
 func (f *FileHandler) serve() {
 	for msg := range f.ch {
-		_, _ = f.Write(msg)
+		if f.aborted.Load() { // drain timeout exceeded
+			continue
+		}
+		f.writeMsg(msg)
 	}
+	f.closeFiles()
 	f.close()
 }
+
+const (
+	minReopenBackoff = 10 * time.Millisecond // wait before reopening again after first failed reopen
+	maxReopenBackoff = time.Second           // max wait between two reopens while writes keep failing
+)
+
+// writeMsg write queued message, drop it when disk space is low
+// log file is reopened when write fails, reopens are backed off exponentially while failure lasts instead of per message,
+// message goes to fallback if write fails
+func (f *FileHandler) writeMsg(msg []byte) {
+	if f.lowDisk() {
+		return
+	}
+
+	_, err := f.writeFile(msg)
+	if err != nil && !time.Now().Before(f.reopenAfter) {
+		if err = f.reopen(); err == nil {
+			_, err = f.writeFile(msg)
+		}
+		if err != nil {
+			if f.reopenBackoff *= 2; f.reopenBackoff < minReopenBackoff {
+				f.reopenBackoff = minReopenBackoff
+			} else if f.reopenBackoff > maxReopenBackoff {
+				f.reopenBackoff = maxReopenBackoff
+			}
+			f.reopenAfter = time.Now().Add(f.reopenBackoff)
+		}
+	}
+	if err != nil {
+		_, _ = f.fallback.Write(msg)
+	} else {
+		f.reopenBackoff, f.reopenAfter = 0, time.Time{}
+	}
+	_ = f.writeExtra(msg)
+}
+
+// lowDisk report whether free disk space is below threshold, checked at most once per second
+func (f *FileHandler) lowDisk() bool {
+	if f.minFreeDisk == 0 {
+		return false
+	}
+
+	f.diskMu.Lock()
+	defer f.diskMu.Unlock()
+	if time.Since(f.diskCheckedAt) < time.Second {
+		return f.diskLow
+	}
+	f.diskCheckedAt = time.Now()
+
+	free, err := f.diskFree(f.Dir)
+	if err != nil { // unable to check, keep writing
+		f.diskLow = false
+		return false
+	}
+
+	low := free < f.minFreeDisk
+	if low && !f.diskLow {
+		fmt.Fprintf(os.Stderr, "log dir %s free disk space %d MB below %d MB, stop writing log\n", f.Dir, free>>20, f.minFreeDisk>>20)
+	}
+	f.diskLow = low
+	return low
+}
@@ -1,7 +1,14 @@
 package log
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
 	"testing"
 	"time"
 )
@@ -45,6 +52,127 @@ func Test_FileName(t *testing.T) {
 	t.Logf("handler file name: %s", fileHandler.FileName())
 }
 
+func TestFileHandler_RegisterOutput(t *testing.T) {
+	handler, err := NewFileHandler(TraceLevel, t.TempDir())
+	if err != nil {
+		t.Fatalf("create new file handler fail: %s", err)
+	}
+
+	var buf1, buf2 bytes.Buffer
+	handler.RegisterOutput(&buf1)
+	handler.RegisterOutput(&buf2)
+	if _, err := handler.Write([]byte("hello\n")); err != nil {
+		t.Fatalf("write fail: %s", err)
+	}
+
+	handler.SetOutput(&buf2)
+	if _, err := handler.Write([]byte("world\n")); err != nil {
+		t.Fatalf("write fail: %s", err)
+	}
+
+	data, _ := os.ReadFile(handler.FileName())
+	for _, testcase := range []struct{ name, got, expected string }{
+		{"file", string(data), "hello\nworld\n"},
+		{"buf1", buf1.String(), "hello\n"},
+		{"buf2", buf2.String(), "hello\nworld\n"},
+	} {
+		if testcase.got != testcase.expected {
+			t.Errorf("unexpected %s content: %q, expect: %q", testcase.name, testcase.got, testcase.expected)
+		}
+	}
+}
+
+func TestFileHandler_Close(t *testing.T) {
+	handler, err := NewFileHandler(TraceLevel, t.TempDir(), FileHandlerFormatter(NewStreamFormatter(false)))
+	if err != nil {
+		t.Fatalf("create new file handler fail: %s", err)
+	}
+
+	const count = 1000
+	for i := 0; i < count; i++ {
+		handler.Output(InfoLevel, nil, "message %d", i)
+	}
+	handler.Close()
+
+	data, _ := os.ReadFile(handler.FileName())
+	if lines := bytes.Count(data, []byte("\n")); lines != count {
+		t.Errorf("expect %d lines flushed before close returns, got: %d", count, lines)
+	}
+	if !bytes.Contains(data, []byte(fmt.Sprintf("message %d\n", count-1))) {
+		t.Errorf("expect last message written")
+	}
+	if _, err := handler.out.Write([]byte("after close")); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("expect log file closed, got: %v", err)
+	}
+}
+
+func TestFileHandler_DrainTimeout(t *testing.T) {
+	handler, err := NewFileHandler(TraceLevel, t.TempDir(), FileHandlerDrainTimeout(20*time.Millisecond))
+	if err != nil {
+		t.Fatalf("create new file handler fail: %s", err)
+	}
+
+	release := make(chan struct{})
+	var extra syncBuffer
+	handler.RegisterOutput(&blockWriter{Writer: &extra, release: release})
+
+	for i := 0; i < 10; i++ {
+		handler.Output(InfoLevel, nil, "message %d", i)
+	}
+
+	start := time.Now()
+	handler.Close()
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("close should return after drain timeout, took %s", elapsed)
+	}
+
+	// messages left are dropped and files closed after message being written returns
+	close(release)
+	select {
+	case <-handler.closed:
+	case <-time.After(time.Second):
+		t.Fatal("serve goroutine not stopped after drain timeout")
+	}
+	if n := strings.Count(extra.String(), "message"); n != 1 {
+		t.Errorf("expect only message being written when timeout written, got %d", n)
+	}
+	if _, err := handler.out.Write([]byte("after close")); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("expect log file closed, got: %v", err)
+	}
+}
+
+func TestFileHandler_GetStats(t *testing.T) {
+	handler, err := NewFileHandler(TraceLevel, t.TempDir(), FileHandlerInterval(0))
+	if err != nil {
+		t.Fatalf("create new file handler fail: %s", err)
+	}
+	if stats := handler.GetStats(); stats != (FileHandlerStats{}) {
+		t.Errorf("expect empty stats before first write, got: %+v", stats)
+	}
+
+	msg := []byte("0123456789\n")
+	for i := 0; i < 5; i++ {
+		if _, err := handler.Write(msg); err != nil {
+			t.Fatalf("write fail: %s", err)
+		}
+	}
+	first := handler.GetStats()
+	if first.BytesWritten != 5*int64(len(msg)) || first.RotationCount != 0 || first.CurrentFilePath != handler.FileName() || first.OpenedAt.IsZero() {
+		t.Errorf("unexpected stats: %+v", first)
+	}
+
+	// switch to hourly file name to force rotation
+	handler.intervalLevel = IntervalHour
+	if _, err := handler.Write(msg); err != nil {
+		t.Fatalf("write fail: %s", err)
+	}
+	stats := handler.GetStats()
+	if stats.BytesWritten != 6*int64(len(msg)) || stats.RotationCount != 1 || stats.CurrentFilePath != handler.FileName() ||
+		stats.CurrentFilePath == first.CurrentFilePath || stats.OpenedAt.Before(first.OpenedAt) {
+		t.Errorf("unexpected stats after rotation: %+v", stats)
+	}
+}
+
 func TestLog(t *testing.T) {
 	handler, err := NewFileHandler(TraceLevel, "/tmp/testlog", FileHandlerInterval(time.Minute))
 	fileHandler := handler
@@ -60,3 +188,210 @@ func TestLog(t *testing.T) {
 		fileHandler.Output(InfoLevel, context.TODO(), "log count: %d", count)
 	}
 }
+
+func TestFileHandler_MinFreeDisk(t *testing.T) {
+	handler, err := NewFileHandler(TraceLevel, t.TempDir(), FileHandlerMinFreeDisk(100))
+	if err != nil {
+		t.Fatalf("create new file handler fail: %s", err)
+	}
+	var free uint64 = 10 << 20
+	handler.diskFree = func(string) (uint64, error) { return free, nil }
+
+	handler.writeMsg([]byte("dropped\n"))
+
+	free = 200 << 20
+	handler.diskCheckedAt = time.Time{} // skip check interval
+	handler.writeMsg([]byte("written\n"))
+
+	data, _ := os.ReadFile(handler.FileName())
+	if string(data) != "written\n" {
+		t.Errorf("expect only message written with enough disk space, got: %q", data)
+	}
+
+	if free, err := freeDiskBytes(t.TempDir()); runtime.GOOS == "linux" && (err != nil || free == 0) {
+		t.Errorf("check free disk fail: %d, %v", free, err)
+	}
+}
+
+func TestFileHandler_reopen(t *testing.T) {
+	dir := t.TempDir()
+	handler, err := NewFileHandler(TraceLevel, dir, FileHandlerInterval(0))
+	if err != nil {
+		t.Fatalf("create new file handler fail: %s", err)
+	}
+	var fallback bytes.Buffer
+	handler.fallback = &fallback
+
+	handler.writeMsg([]byte("first\n"))
+	if err := os.Remove(handler.FileName()); err != nil {
+		t.Fatalf("remove log file fail: %s", err)
+	}
+	handler.writeMsg([]byte("second\n"))
+
+	if data, _ := os.ReadFile(handler.FileName()); string(data) != "second\n" {
+		t.Errorf("expect message written to reopened file, got: %q", data)
+	}
+	if stats := handler.GetStats(); stats.ReopenCount != 1 || stats.RotationCount != 0 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+
+	// log dir replaced by file, reopening always fails
+	_ = os.RemoveAll(dir)
+	if err := os.WriteFile(dir, nil, 0644); err != nil {
+		t.Fatalf("replace log dir fail: %s", err)
+	}
+	handler.writeMsg([]byte("third\n"))
+	if fallback.String() != "third\n" {
+		t.Errorf("expect message written to fallback, got: %q", fallback.String())
+	}
+
+	// reopen is backed off during failure instead of sleeping for every message
+	start := time.Now()
+	for i := 0; i < 100; i++ {
+		handler.writeMsg([]byte("failed\n"))
+	}
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Errorf("writing during failure should not sleep, took %s", elapsed)
+	}
+	if n := strings.Count(fallback.String(), "failed\n"); n != 100 {
+		t.Errorf("expect 100 messages written to fallback, got %d", n)
+	}
+
+	// recovered after backoff passed
+	_ = os.Remove(dir)
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatalf("restore log dir fail: %s", err)
+	}
+	time.Sleep(time.Until(handler.reopenAfter))
+	handler.writeMsg([]byte("recovered\n"))
+	if data, _ := os.ReadFile(handler.FileName()); string(data) != "recovered\n" {
+		t.Errorf("expect message written after recovered, got: %q", data)
+	}
+}
+
+func TestFileHandler_mirror(t *testing.T) {
+	dir, mirrorDir := t.TempDir(), t.TempDir()
+	handler, err := NewFileHandler(TraceLevel, dir, FileHandlerInterval(0), FileHandlerMirrorPath(mirrorDir))
+	if err != nil {
+		t.Fatalf("create new file handler fail: %s", err)
+	}
+
+	for i := 0; i < 100; i++ {
+		handler.writeMsg([]byte(fmt.Sprintf("message %d\n", i)))
+	}
+	data, _ := os.ReadFile(handler.FileName())
+	mirrorData, _ := os.ReadFile(filepath.Join(mirrorDir, "log"))
+	if len(data) == 0 || !bytes.Equal(data, mirrorData) {
+		t.Errorf("expect mirror identical to log file, got:\n%q\n%q", data, mirrorData)
+	}
+
+	// mirror rotated along with log file
+	handler.intervalLevel = IntervalHour
+	handler.writeMsg([]byte("rotated\n"))
+	if data, _ := os.ReadFile(filepath.Join(mirrorDir, filepath.Base(handler.FileName()))); string(data) != "rotated\n" {
+		t.Errorf("expect message written to rotated mirror, got: %q", data)
+	}
+
+	// mirror write fails, log file still written
+	_ = handler.mirror.Close()
+	handler.writeMsg([]byte("mirror closed\n"))
+	if data, _ := os.ReadFile(handler.FileName()); string(data) != "rotated\nmirror closed\n" {
+		t.Errorf("expect log file written when mirror fails, got: %q", data)
+	}
+}
+
+func TestFileHandler_mirrorSameDir(t *testing.T) {
+	dir := t.TempDir()
+	handler, err := NewFileHandler(TraceLevel, dir, FileHandlerMirrorPath(dir))
+	if err != nil {
+		t.Fatalf("create new file handler fail: %s", err)
+	}
+	handler.writeMsg([]byte("message\n"))
+
+	mirrorName := strings.TrimSuffix(handler.FileName(), "log") + "mirror.log"
+	if data, _ := os.ReadFile(mirrorName); string(data) != "message\n" {
+		t.Errorf("expect mirror %s written, got: %q", mirrorName, data)
+	}
+}
+
+func TestFileHandler_mirrorOpenFail(t *testing.T) {
+	mirrorDir := filepath.Join(t.TempDir(), "mirror")
+	if err := os.WriteFile(mirrorDir, nil, 0644); err != nil { // mirror dir is a file
+		t.Fatalf("create file fail: %s", err)
+	}
+
+	handler, err := NewFileHandler(TraceLevel, t.TempDir(), FileHandlerMirrorPath(mirrorDir))
+	if err != nil {
+		t.Fatalf("create new file handler fail: %s", err)
+	}
+	handler.writeMsg([]byte("message\n"))
+
+	if data, _ := os.ReadFile(handler.FileName()); string(data) != "message\n" {
+		t.Errorf("expect log file written when mirror fails to open, got: %q", data)
+	}
+}
+
+func TestParseIntervalLevel(t *testing.T) {
+	for _, level := range []IntervalLevel{IntervalNone, IntervalMinute, IntervalHour, IntervalDay} {
+		if parsed, err := ParseIntervalLevel(level.String()); err != nil || parsed != level {
+			t.Errorf("round trip %s fail: %s, %v", level, parsed, err)
+		}
+	}
+	for s, expected := range map[string]IntervalLevel{"Hourly": IntervalHour, " DAILY ": IntervalDay, "minutely": IntervalMinute, "NONE": IntervalNone} {
+		if parsed, err := ParseIntervalLevel(s); err != nil || parsed != expected {
+			t.Errorf("parse %q: expect %s, got %s, %v", s, expected, parsed, err)
+		}
+	}
+	for _, s := range []string{"", "weekly", "monthly", "hours"} {
+		if _, err := ParseIntervalLevel(s); err == nil {
+			t.Errorf("expect error parsing %q", s)
+		}
+	}
+
+	level, _ := ParseIntervalLevel("minute")
+	handler, err := NewFileHandler(TraceLevel, t.TempDir(), FileHandlerIntervalLevel(level))
+	if err != nil {
+		t.Fatalf("create new file handler fail: %s", err)
+	}
+	if expected := time.Now().Format("2006-01-02T_15_04.log"); filepath.Base(handler.FileName()) != expected {
+		t.Errorf("expect file name %s, got %s", expected, handler.FileName())
+	}
+}
+
+func TestFileHandler_LevelSplit(t *testing.T) {
+	dir := t.TempDir()
+	handler, err := NewFileHandler(InfoLevel, dir, FileHandlerInterval(0), FileHandlerLogFilePrefix("app"),
+		FileHandlerFormatter(NewStreamFormatter(false)), FileHandlerLevelSplit())
+	if err != nil {
+		t.Fatalf("create new file handler fail: %s", err)
+	}
+	var extra bytes.Buffer
+	handler.SetOutput(&extra)
+
+	logger := NewLogger(handler)
+	logger.Info("info message")
+	logger.Error("error message")
+	logger.Info("another info")
+	logger.Debug("debug message")
+	logger.Close()
+
+	info, _ := os.ReadFile(filepath.Join(dir, "app.info.log"))
+	if !strings.Contains(string(info), "info message") || !strings.Contains(string(info), "another info") || strings.Contains(string(info), "error message") {
+		t.Errorf("unexpected info log file: %q", info)
+	}
+	errorLog, _ := os.ReadFile(filepath.Join(dir, "app.error.log"))
+	if !strings.Contains(string(errorLog), "error message") || strings.Contains(string(errorLog), "info") {
+		t.Errorf("unexpected error log file: %q", errorLog)
+	}
+
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 2 {
+		t.Errorf("expect only info and error log files, got %d files", len(entries))
+	}
+	if stats := handler.GetStats(); stats.BytesWritten != int64(len(info)+len(errorLog)) {
+		t.Errorf("expect bytes of all files summed, got %+v", stats)
+	}
+	if strings.Count(extra.String(), "\n") != 3 {
+		t.Errorf("expect all messages written to extra output, got: %q", extra.String())
+	}
+}
@@ -1,38 +1,92 @@
 package log
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 )
 
 // Formatter log formatter
 type Formatter interface {
-	Format(l Level, ctx context.Context, format string) string
+	// Format format message already formatted with args into log line,
+	// breaking change: msg was the format string passed to fmt.Sprintf with args after Format before
+	Format(l Level, ctx context.Context, msg string) string
 }
 
-var _ Formatter = (*StreamFormatter)(nil)
+var (
+	_ Formatter = (*StreamFormatter)(nil)
+	_ Formatter = (*JSONFormatter)(nil)
+)
+
+const (
+	// TimestampFormatNano RFC3339 timestamp with sub-second precision
+	TimestampFormatNano = time.RFC3339Nano
+	// TimestampFormatEpochMs unix epoch timestamp in milliseconds
+	TimestampFormatEpochMs = "epoch_ms"
+)
 
 // NewStreamFormatter create new stream formatter
 func NewStreamFormatter(color bool) *StreamFormatter { return &StreamFormatter{color: color} }
 
+// StreamFormatterOptions stream formatter options
+type StreamFormatterOptions struct {
+	// TimestampFormat time layout or TimestampFormatEpochMs, default time.RFC3339
+	TimestampFormat string
+}
+
+// NewStreamFormatterWithOptions create new stream formatter with options
+func NewStreamFormatterWithOptions(color bool, opts StreamFormatterOptions) *StreamFormatter {
+	return &StreamFormatter{color: color, timestampFormat: opts.TimestampFormat}
+}
+
+// DefaultColorMap return new map of default 256-color terminal codes of levels, modify it freely
+func DefaultColorMap() map[Level]int {
+	return map[Level]int{
+		TraceLevel: TraceLevel.Color(),
+		DebugLevel: DebugLevel.Color(),
+		InfoLevel:  InfoLevel.Color(),
+		WarnLevel:  WarnLevel.Color(),
+		ErrorLevel: ErrorLevel.Color(),
+		FatalLevel: FatalLevel.Color(),
+		PanicLevel: PanicLevel.Color(),
+	}
+}
+
+// NewColoredStreamFormatter create new stream formatter with custom 256-color terminal codes copied from colorMap,
+// levels not in colorMap are not colored, so nil or empty colorMap colors nothing,
+// start from DefaultColorMap() to override colors of some levels only
+func NewColoredStreamFormatter(colorMap map[Level]int) *StreamFormatter {
+	colors := make(map[Level]int, len(colorMap))
+	for level, code := range colorMap {
+		colors[level] = code
+	}
+	return &StreamFormatter{color: true, colorMap: colors}
+}
+
 // StreamFormattera stream formatter
 type StreamFormatter struct {
-	color bool
+	color    bool
+	colorMap map[Level]int // custom colors, use Level.Color() if nil
+
+	timestampFormat string
 }
 
 // Format format log
-func (f *StreamFormatter) Format(l Level, ctx context.Context, format string) string {
+func (f *StreamFormatter) Format(l Level, ctx context.Context, msg string) string {
 	var buf strings.Builder
 
-	if f.color {
+	code, colored := f.colorCode(l)
+	if colored {
 		buf.WriteString("\033[38;5;")
-		buf.WriteString(fmt.Sprint(l.Color()))
+		buf.WriteString(fmt.Sprint(code))
 		buf.WriteString("m")
 	}
 
-	buf.WriteString(time.Now().Format(time.RFC3339))
+	buf.WriteString(f.timestamp(time.Now()))
 	buf.WriteByte(' ')
 
 	buf.WriteByte('[')
@@ -40,14 +94,18 @@ func (f *StreamFormatter) Format(l Level, ctx context.Context, format string) st
 	buf.WriteByte(']')
 	buf.WriteByte(' ')
 
-	if logID := f.getLogID(ctx); logID != "" {
+	if logID := getLogID(ctx); logID != "" {
 		buf.WriteString(logID)
 		buf.WriteByte(' ')
 	}
+	if fields := logFields(ctx); len(fields) > 0 {
+		buf.WriteString(formatFields(fields))
+		buf.WriteByte(' ')
+	}
 
-	buf.WriteString(format)
+	buf.WriteString(msg)
 
-	if f.color {
+	if colored {
 		buf.WriteString("\033[0m")
 	}
 
@@ -56,7 +114,30 @@ func (f *StreamFormatter) Format(l Level, ctx context.Context, format string) st
 	return buf.String()
 }
 
-func (f *StreamFormatter) getLogID(ctx context.Context) string {
+func (f *StreamFormatter) colorCode(l Level) (code int, ok bool) {
+	switch {
+	case !f.color:
+		return 0, false
+	case f.colorMap != nil:
+		code, ok = f.colorMap[l]
+		return code, ok
+	default:
+		return l.Color(), true
+	}
+}
+
+func (f *StreamFormatter) timestamp(t time.Time) string {
+	switch f.timestampFormat {
+	case "":
+		return t.Format(time.RFC3339)
+	case TimestampFormatEpochMs:
+		return strconv.FormatInt(t.UnixMilli(), 10)
+	default:
+		return t.Format(f.timestampFormat)
+	}
+}
+
+func getLogID(ctx context.Context) string {
 	if ctx == nil {
 		return ""
 	}
@@ -65,3 +146,74 @@ func (f *StreamFormatter) getLogID(ctx context.Context) string {
 	}
 	return ""
 }
+
+// NewJSONFormatter create new json formatter, every log is a json object in one line like
+// {"time":"2006-01-02T15:04:05.999999999Z07:00","level":"info","log_id":"abc","msg":"message","fields":{"k":"v"}}
+// log_id and fields are omitted when empty
+func NewJSONFormatter() *JSONFormatter { return &JSONFormatter{} }
+
+// JSONFormatter json formatter
+type JSONFormatter struct{}
+
+// Format format log
+func (f *JSONFormatter) Format(l Level, ctx context.Context, msg string) string {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	// write value as json, value can not be marshaled like NaN or Inf is written as string
+	write := func(v any) {
+		if err := enc.Encode(v); err != nil {
+			_ = enc.Encode(fmt.Sprint(v))
+		}
+		buf.Truncate(buf.Len() - 1) // trim newline appended by Encode
+	}
+
+	buf.WriteString(`{"time":`)
+	write(time.Now().Format(time.RFC3339Nano))
+	buf.WriteString(`,"level":`)
+	write(strings.ToLower(l.String()))
+	if logID := getLogID(ctx); logID != "" {
+		buf.WriteString(`,"log_id":`)
+		write(logID)
+	}
+	buf.WriteString(`,"msg":`)
+	write(msg)
+
+	if fields := logFields(ctx); len(fields) > 0 {
+		buf.WriteString(`,"fields":{`)
+		for i := 0; i < len(fields); i += 2 {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			write(fmt.Sprint(fields[i]))
+			buf.WriteByte(':')
+			if i+1 < len(fields) {
+				write(fields[i+1])
+			} else {
+				write("MISSING")
+			}
+		}
+		buf.WriteByte('}')
+	}
+	buf.WriteString("}\n")
+
+	return buf.String()
+}
+
+// formatFields format key-value fields like k1=v1 k2=v2, value of odd field is missing
+func formatFields(fields []any) string {
+	var buf strings.Builder
+	for i := 0; i < len(fields); i += 2 {
+		if i > 0 {
+			buf.WriteByte(' ')
+		}
+		buf.WriteString(fmt.Sprint(fields[i]))
+		buf.WriteByte('=')
+		if i+1 < len(fields) {
+			buf.WriteString(fmt.Sprint(fields[i+1]))
+		} else {
+			buf.WriteString("MISSING")
+		}
+	}
+	return buf.String()
+}
@@ -0,0 +1,143 @@
+package log
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStreamFormatter_TimestampFormat(t *testing.T) {
+	for _, testcase := range []struct {
+		format string
+		parse  func(string) error
+	}{
+		{"", func(s string) error { _, err := time.Parse(time.RFC3339, s); return err }},
+		{TimestampFormatNano, func(s string) error { _, err := time.Parse(time.RFC3339Nano, s); return err }},
+		{TimestampFormatEpochMs, func(s string) error {
+			ms, err := strconv.ParseInt(s, 10, 64)
+			if err == nil && time.Since(time.UnixMilli(ms)) > time.Minute {
+				t.Errorf("unexpected epoch ms timestamp: %s", s)
+			}
+			return err
+		}},
+		{"2006/01/02-15:04:05", func(s string) error { _, err := time.Parse("2006/01/02-15:04:05", s); return err }},
+	} {
+		formatter := NewStreamFormatterWithOptions(false, StreamFormatterOptions{TimestampFormat: testcase.format})
+		output := formatter.Format(InfoLevel, nil, "message")
+
+		timestamp, _, _ := strings.Cut(output, " ")
+		if err := testcase.parse(timestamp); err != nil {
+			t.Errorf("format %q: unexpected timestamp %q in output %q: %s", testcase.format, timestamp, output, err)
+		}
+	}
+}
+
+func TestNewColoredStreamFormatter(t *testing.T) {
+	formatter := NewColoredStreamFormatter(map[Level]int{InfoLevel: 82, ErrorLevel: 9})
+
+	if output := formatter.Format(InfoLevel, nil, "message"); !strings.HasPrefix(output, "\033[38;5;82m") || !strings.Contains(output, "\033[0m") {
+		t.Errorf("expect info message colored with 82, got: %q", output)
+	}
+	if output := formatter.Format(ErrorLevel, nil, "message"); !strings.HasPrefix(output, "\033[38;5;9m") {
+		t.Errorf("expect error message colored with 9, got: %q", output)
+	}
+	if output := formatter.Format(WarnLevel, nil, "message"); strings.Contains(output, "\033[") {
+		t.Errorf("expect warn message uncolored, got: %q", output)
+	}
+
+	for _, colorMap := range []map[Level]int{nil, {}} {
+		formatter = NewColoredStreamFormatter(colorMap)
+		for level := TraceLevel; level <= PanicLevel; level++ {
+			if output := formatter.Format(level, nil, "message"); strings.Contains(output, "\033[") {
+				t.Errorf("expect %s message uncolored with color map %v, got: %q", level, colorMap, output)
+			}
+		}
+	}
+
+	colorMap := DefaultColorMap()
+	colorMap[InfoLevel] = 82
+	formatter = NewColoredStreamFormatter(colorMap)
+	if output := formatter.Format(WarnLevel, nil, "message"); !strings.HasPrefix(output, "\033[38;5;148m") {
+		t.Errorf("expect default color map same as default colors, got: %q", output)
+	}
+	if output := formatter.Format(InfoLevel, nil, "message"); !strings.HasPrefix(output, "\033[38;5;82m") {
+		t.Errorf("expect info message colored with 82, got: %q", output)
+	}
+	if code := DefaultColorMap()[InfoLevel]; code != InfoLevel.Color() {
+		t.Errorf("expect default color map unaffected by modifying returned one, got %d", code)
+	}
+}
+
+func TestWithLogFields(t *testing.T) {
+	ctx := WithLogFields(context.WithValue(context.Background(), "log_id", "abc"), "user_id", 42)
+	tenantCtx := WithLogFields(ctx, "tenant_id", "100%")
+
+	var buf syncBuffer
+	logger := NewLoggerWithWriter(&buf, InfoLevel)
+	logger.CtxInfo(ctx, "first")
+	logger.CtxInfo(tenantCtx, "second %d", 2)
+	logger.Close()
+
+	if !waitFor(time.Second, func() bool { return strings.Contains(buf.String(), "second") }) {
+		t.Fatalf("expect messages in output, got: %q", buf.String())
+	}
+	for _, msg := range []string{"abc user_id=42 first", "abc user_id=42 tenant_id=100% second 2"} {
+		if !strings.Contains(buf.String(), msg) {
+			t.Errorf("expect %q in output, got: %q", msg, buf.String())
+		}
+	}
+}
+
+func TestJSONFormatter(t *testing.T) {
+	formatter := NewJSONFormatter()
+
+	var entry map[string]any
+	output := formatter.Format(WarnLevel, nil, "quote \" backslash \\ null \x00 bell \x07 <tag>")
+	if !strings.HasSuffix(output, "}\n") || json.Unmarshal([]byte(output), &entry) != nil {
+		t.Fatalf("invalid json output: %q", output)
+	}
+	if entry["msg"] != "quote \" backslash \\ null \x00 bell \x07 <tag>" || entry["level"] != "warn" {
+		t.Errorf("unexpected entry: %v", entry)
+	}
+	if _, ok := entry["log_id"]; ok {
+		t.Errorf("empty log_id should be omitted: %q", output)
+	}
+	if ts, _ := entry["time"].(string); ts == "" {
+		t.Errorf("missing time: %q", output)
+	} else if _, err := time.Parse(time.RFC3339Nano, ts); err != nil {
+		t.Errorf("time not in RFC3339Nano: %s", ts)
+	}
+
+	ctx := WithLogFields(context.WithValue(context.Background(), "log_id", "abc"), "big", math.MaxFloat64, "nan", math.NaN(), "inf", math.Inf(-1), "odd")
+	output = formatter.Format(InfoLevel, ctx, "100%")
+	entry = nil
+	if err := json.Unmarshal([]byte(output), &entry); err != nil {
+		t.Fatalf("invalid json output: %q", output)
+	}
+	if entry["log_id"] != "abc" || entry["msg"] != "100%" {
+		t.Errorf("unexpected entry: %v", entry)
+	}
+	if expected := `"fields":{"big":1.7976931348623157e+308,"nan":"NaN","inf":"-Inf","odd":"MISSING"}`; !strings.Contains(output, expected) {
+		t.Errorf("expect %s in output, got: %s", expected, output)
+	}
+}
+
+func FuzzJSONFormatter(f *testing.F) {
+	f.Add("message %s %v", "arg", 1.5)
+	f.Add("%q\x00\"\\", "\x1f ", math.MaxFloat64)
+	f.Add("%d%%", "\xff\xfe", math.Inf(1))
+
+	formatter := NewJSONFormatter()
+	f.Fuzz(func(t *testing.T, format, arg string, num float64) {
+		ctx := WithLogFields(context.WithValue(context.Background(), "log_id", arg), arg, num, "arg", arg)
+		output := formatter.Format(InfoLevel, ctx, fmt.Sprintf(format, arg, num))
+		if !json.Valid([]byte(output)) {
+			t.Errorf("invalid json output: %q", output)
+		}
+	})
+}
@@ -3,8 +3,27 @@ package log
 import (
 	"context"
 	"io"
+	"time"
 )
 
+type logFieldsKey struct{}
+
+// WithLogFields return context carrying key-value fields, which are appended to fields carried by ctx
+// fields are output after log_id by every log call with returned context
+func WithLogFields(ctx context.Context, fields ...any) context.Context {
+	exist := logFields(ctx)
+	return context.WithValue(ctx, logFieldsKey{}, append(exist[:len(exist):len(exist)], fields...))
+}
+
+// logFields return fields carried by ctx
+func logFields(ctx context.Context) []any {
+	if ctx == nil {
+		return nil
+	}
+	fields, _ := ctx.Value(logFieldsKey{}).([]any)
+	return fields
+}
+
 // Flush flush log
 func Flush() { defaultLogger.Flush() }
 
@@ -23,9 +42,34 @@ func SetLevel(l Level) { defaultHandler.SetLevel(l) }
 // RegisterOutput register log output
 func RegisterOutput(out io.Writer) { defaultHandler.RegisterOutput(out) }
 
+// AddOutput register log output, alias of RegisterOutput
+func AddOutput(out io.Writer) { RegisterOutput(out) }
+
+// AddOutputs register log outputs
+func AddOutputs(outs ...io.Writer) {
+	for _, out := range outs {
+		RegisterOutput(out)
+	}
+}
+
 // SetOutput set log output
 func SetOutput(out io.Writer) { defaultHandler.SetOutput(out) }
 
+// SetTeeOutput set default logger output to both stdout and rolling log files, replacing handlers registered before
+func SetTeeOutput(level Level, dir, filename string, interval time.Duration) error {
+	fileHandler, err := newTeeFileHandler(level, dir, filename, interval)
+	if err != nil {
+		return err
+	}
+	defaultHandler.SetLevel(level)
+	defaultLogger.ClearHandler()
+	defaultLogger.RegisterHandler(defaultHandler, fileHandler)
+	return nil
+}
+
+// Named return default logger with prefix [name]
+func Named(name string) Logger { return defaultLogger.Named(name) }
+
 // Trace ...
 func Trace(format string, args ...interface{}) {
 	defaultLogger.Trace(format, args...)
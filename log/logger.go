@@ -3,7 +3,10 @@ package log
 import (
 	"context"
 	"io"
+	"os"
+	"strings"
 	"sync"
+	"time"
 )
 
 var (
@@ -14,6 +17,32 @@ var (
 // NewLogger new logger
 func NewLogger(handlers ...Handler) Logger { return &logger{handlers: handlers} }
 
+// NewLoggerWithWriter new logger output to w
+func NewLoggerWithWriter(w io.Writer, level Level) Logger {
+	handler := NewStreamHandler(level)
+	handler.SetOutput(w)
+	return NewLogger(handler)
+}
+
+// NewStderrLogger new logger output to stderr
+func NewStderrLogger(level Level) Logger { return NewLoggerWithWriter(os.Stderr, level) }
+
+// NewTeeLogger new logger output to both stdout and rolling log files named with filename in dir
+func NewTeeLogger(level Level, dir, filename string, interval time.Duration) (Logger, error) {
+	fileHandler, err := newTeeFileHandler(level, dir, filename, interval)
+	if err != nil {
+		return nil, err
+	}
+	return NewLogger(NewStreamHandler(level), fileHandler), nil
+}
+
+func newTeeFileHandler(level Level, dir, filename string, interval time.Duration) (*FileHandler, error) {
+	return NewFileHandler(level, dir,
+		FileHandlerLogFilePrefix(filename),
+		FileHandlerInterval(interval),
+		FileHandlerFormatter(NewStreamFormatter(false)))
+}
+
 // Logger logger interface
 type Logger interface {
 	RegisterHandler(...Handler)
@@ -21,6 +50,14 @@ type Logger interface {
 
 	SetLevel(Level)
 
+	// AddOutput register output w to all handlers, same as RegisterOutput of handlers
+	AddOutput(w io.Writer)
+	// AddOutputs register writers to all handlers
+	AddOutputs(writers ...io.Writer)
+
+	// Named return logger prepends [name] to every message, names stack like [db][pool]
+	Named(name string) Logger
+
 	Flush()
 	Close()
 
@@ -51,22 +88,43 @@ type Handler interface {
 	Close()
 
 	RegisterOutput(io.Writer)
+	AddOutput(io.Writer) // alias of RegisterOutput
+	AddOutputs(...io.Writer)
 	SetOutput(io.Writer)
 }
 
+var _ Logger = (*logger)(nil)
+
 type logger struct {
 	mu       sync.RWMutex
 	handlers []Handler
+
+	prefix string // prefix built by Named, % escaped
+	closed bool   // messages are dropped after logger closed
 }
 
 func (l *logger) SetLevel(level Level) {
 	l.mu.RLock()
-	defer l.mu.RLock()
+	defer l.mu.RUnlock()
 	for _, handler := range l.handlers {
 		handler.SetLevel(level)
 	}
 }
 
+func (l *logger) AddOutput(w io.Writer) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	for _, handler := range l.handlers {
+		handler.RegisterOutput(w)
+	}
+}
+
+func (l *logger) AddOutputs(writers ...io.Writer) {
+	for _, w := range writers {
+		l.AddOutput(w)
+	}
+}
+
 func (l *logger) RegisterHandler(handlers ...Handler) {
 	l.mu.Lock()
 	defer l.mu.Unlock()
@@ -79,13 +137,39 @@ func (l *logger) ClearHandler() {
 	l.handlers = make([]Handler, 0, 4)
 }
 
+func (l *logger) Named(name string) Logger {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return &logger{
+		handlers: append(make([]Handler, 0, len(l.handlers)), l.handlers...),
+		prefix:   l.prefix + "[" + strings.ReplaceAll(name, "%", "%%") + "]",
+	}
+}
+
 func (l *logger) Flush() {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if l.closed {
+		return
+	}
 	for _, handler := range l.handlers {
 		handler.Flush()
 	}
 }
+
+// Close mark logger closed under write lock, so no message is being output when handlers close,
+// handlers are closed after lock released to avoid blocking other loggers sharing them
 func (l *logger) Close() {
-	for _, handler := range l.handlers {
+	l.mu.Lock()
+	if l.closed {
+		l.mu.Unlock()
+		return
+	}
+	l.closed = true
+	handlers := l.handlers
+	l.mu.Unlock()
+
+	for _, handler := range handlers {
 		handler.Close()
 	}
 }
@@ -121,6 +205,15 @@ func (l *logger) CtxPanic(ctx context.Context, format string, v ...any) {
 }
 
 func (l *logger) output(level Level, ctx context.Context, format string, v ...any) {
+	if l.prefix != "" {
+		format = l.prefix + " " + format
+	}
+
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if l.closed {
+		return
+	}
 	for _, handler := range l.handlers {
 		handler.Output(level, ctx, format, v...)
 	}
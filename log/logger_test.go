@@ -0,0 +1,150 @@
+package log
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestNewLoggerWithWriter(t *testing.T) {
+	var buf syncBuffer
+	logger := NewLoggerWithWriter(&buf, InfoLevel)
+
+	logger.Debug("debug message")
+	logger.Info("info message: %d", 1)
+	logger.Close()
+
+	if !waitFor(time.Second, func() bool { return strings.Contains(buf.String(), "info message: 1") }) {
+		t.Errorf("expect info message in output, got: %q", buf.String())
+	}
+	if strings.Contains(buf.String(), "debug message") {
+		t.Errorf("debug message should be filtered, got: %q", buf.String())
+	}
+}
+
+func TestLogger_AddOutput(t *testing.T) {
+	var buf, added, first, second syncBuffer
+	logger := NewLoggerWithWriter(&buf, InfoLevel)
+	logger.AddOutput(&added)
+	logger.AddOutputs(&first, &second)
+
+	logger.Info("info message")
+	logger.Close()
+
+	for name, b := range map[string]*syncBuffer{"origin": &buf, "added": &added, "first": &first, "second": &second} {
+		if !strings.Contains(b.String(), "info message") {
+			t.Errorf("expect message in %s output, got: %q", name, b.String())
+		}
+	}
+}
+
+func TestLogger_Close(t *testing.T) {
+	var buf syncBuffer
+	logger := NewLoggerWithWriter(&buf, InfoLevel)
+
+	// SetLevel must release its lock, or RegisterHandler below blocks forever
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		logger.SetLevel(InfoLevel)
+		logger.RegisterHandler()
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("logger lock not released by SetLevel")
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				logger.Info("concurrent message")
+			}
+		}()
+	}
+	logger.Info("before close")
+	logger.Close()
+	logger.Close()
+	wg.Wait()
+
+	logger.Info("after close")
+	logger.Flush()
+	if !waitFor(time.Second, func() bool { return strings.Contains(buf.String(), "before close") }) {
+		t.Fatalf("expect message before close in output, got: %q", buf.String())
+	}
+	if strings.Contains(buf.String(), "after close") {
+		t.Errorf("message after close should be dropped")
+	}
+}
+
+func TestLogger_Named(t *testing.T) {
+	var buf syncBuffer
+	logger := NewLoggerWithWriter(&buf, InfoLevel)
+
+	logger.Named("db").Named("pool").Info("connect %s", "ok")
+	logger.Named("100%").Info("done")
+	logger.Info("plain")
+	logger.Close()
+
+	if !waitFor(time.Second, func() bool { return strings.Contains(buf.String(), "plain") }) {
+		t.Fatalf("expect messages in output, got: %q", buf.String())
+	}
+	for _, msg := range []string{"[db][pool] connect ok", "[100%] done"} {
+		if !strings.Contains(buf.String(), msg) {
+			t.Errorf("expect %q in output, got: %q", msg, buf.String())
+		}
+	}
+	if !strings.Contains(buf.String(), "[INFO] plain") {
+		t.Errorf("parent logger should not be prefixed, got: %q", buf.String())
+	}
+}
+
+func TestLogger_Named_handlers(t *testing.T) {
+	parentHandler, registered := newRecordHandler(), newRecordHandler()
+	parent := NewLogger(parentHandler)
+	named := parent.Named("auth")
+
+	parent.RegisterHandler(registered)
+	named.Info("login %s", "ok")
+	if msgs := registered.messages(); len(msgs) != 0 {
+		t.Errorf("handler registered to parent should not receive messages of named logger, got: %q", msgs)
+	}
+
+	parent.ClearHandler()
+	named.Info("logout")
+	if msgs := parentHandler.messages(); fmt.Sprint(msgs) != fmt.Sprint([]string{"Info [auth] login ok", "Info [auth] logout"}) {
+		t.Errorf("named logger should keep handlers of parent when derived, got: %q", msgs)
+	}
+}
+
+func TestNewTeeLogger(t *testing.T) {
+	dir := t.TempDir()
+	tee, err := NewTeeLogger(InfoLevel, dir, "app", time.Hour)
+	if err != nil {
+		t.Fatalf("create tee logger fail: %s", err)
+	}
+
+	var buf syncBuffer
+	handlers := tee.(*logger).handlers
+	handlers[0].SetOutput(&buf)
+	fileName := handlers[1].(*FileHandler).FileName()
+
+	tee.Info("hello %s", "tee")
+	tee.Close()
+
+	if !waitFor(time.Second, func() bool { return strings.Contains(buf.String(), "hello tee") }) {
+		t.Errorf("expect message in console output, got: %q", buf.String())
+	}
+	if !strings.HasPrefix(fileName, dir+"/app.") {
+		t.Errorf("unexpected log file name: %s", fileName)
+	}
+	if data, _ := os.ReadFile(fileName); !strings.Contains(string(data), "hello tee") || strings.Contains(string(data), "\x1b[") {
+		t.Errorf("expect uncolored message in log file, got: %q", data)
+	}
+}
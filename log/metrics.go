@@ -0,0 +1,52 @@
+package log
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+var _ Handler = (*metricsHandler)(nil)
+
+// LogMetrics receive log message counts, implement it to connect any metrics library, e.g. prometheus CounterVec
+type LogMetrics interface {
+	// IncMessage called for every message dispatched to handler
+	IncMessage(level Level)
+}
+
+// NewMetricsHandler wrap inner handler, counting every message dispatched to it by level,
+// messages filtered by level of inner handler are counted too
+func NewMetricsHandler(inner Handler, metrics LogMetrics) Handler {
+	return &metricsHandler{Handler: inner, metrics: metrics}
+}
+
+type metricsHandler struct {
+	Handler
+	metrics LogMetrics
+}
+
+func (h *metricsHandler) Output(level Level, ctx context.Context, format string, v ...any) {
+	h.metrics.IncMessage(level)
+	h.Handler.Output(level, ctx, format, v...)
+}
+
+var _ LogMetrics = (*LevelCounter)(nil)
+
+// LevelCounter in-memory LogMetrics counting messages per level
+type LevelCounter struct {
+	counts [PanicLevel + 1]uint64
+}
+
+// IncMessage increase count of level
+func (c *LevelCounter) IncMessage(level Level) {
+	if level <= PanicLevel {
+		atomic.AddUint64(&c.counts[level], 1)
+	}
+}
+
+// Count return count of level
+func (c *LevelCounter) Count(level Level) uint64 {
+	if level > PanicLevel {
+		return 0
+	}
+	return atomic.LoadUint64(&c.counts[level])
+}
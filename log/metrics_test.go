@@ -0,0 +1,25 @@
+package log
+
+import (
+	"io"
+	"testing"
+)
+
+func TestNewMetricsHandler(t *testing.T) {
+	var counter LevelCounter
+	handler := NewStreamHandler(InfoLevel)
+	handler.SetOutput(io.Discard)
+
+	logger := NewLogger(NewMetricsHandler(handler, &counter))
+	logger.Info("info 1")
+	logger.Info("info 2")
+	logger.Error("error")
+	logger.Debug("debug")
+	logger.Close()
+
+	for level, expected := range map[Level]uint64{InfoLevel: 2, ErrorLevel: 1, DebugLevel: 1, WarnLevel: 0} {
+		if count := counter.Count(level); count != expected {
+			t.Errorf("%s: expect count %d, got %d", level, expected, count)
+		}
+	}
+}
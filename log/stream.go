@@ -7,17 +7,36 @@ import (
 	"os"
 	"runtime"
 	"sync"
+	"sync/atomic"
 )
 
 var _ Handler = (*StreamHandler)(nil)
 
 // NewStreamHandler create new stream handler
 func NewStreamHandler(level Level) *StreamHandler {
+	return NewStreamHandlerWithBufferSize(level, defaultStreamBufferSize)
+}
+
+const defaultStreamBufferSize = 8 * 1024
+
+// NewJSONStreamHandler create new stream handler output logs as json lines
+func NewJSONStreamHandler(level Level) *StreamHandler {
+	s := NewStreamHandler(level)
+	s.Formatter = NewJSONFormatter()
+	return s
+}
+
+// NewStreamHandlerWithBufferSize create new stream handler buffering at most bufSize messages,
+// Output blocks when buffer is full unless SetDropOnFull, non-positive bufSize means default size 8192
+func NewStreamHandlerWithBufferSize(level Level, bufSize int) *StreamHandler {
+	if bufSize <= 0 {
+		bufSize = defaultStreamBufferSize
+	}
 	return &StreamHandler{
 		Formatter: NewStreamFormatter(true),
 
 		level: level,
-		ch:    make(chan []byte, 8*1024),
+		ch:    make(chan []byte, bufSize),
 		out:   os.Stdout,
 
 		closed: make(chan struct{}),
@@ -26,12 +45,17 @@ func NewStreamHandler(level Level) *StreamHandler {
 
 // StreamHandler stream log handler
 type StreamHandler struct {
-	Formatter
+	Formatter // use SetFormatter to replace formatter while logging
+
+	formatterMu sync.RWMutex
 
 	level Level
 	ch    chan []byte
 	out   io.Writer // thread-unsafe
 
+	dropOnFull atomic.Bool
+	dropped    atomic.Int64
+
 	closeOnce sync.Once
 	closed    chan struct{}
 
@@ -43,16 +67,53 @@ func (s *StreamHandler) allowLevel(level Level) bool { return level >= s.level }
 
 func (s *StreamHandler) SetOutput(out io.Writer)      { s.out = out }
 func (s *StreamHandler) RegisterOutput(out io.Writer) { s.out = io.MultiWriter(s.out, out) }
+func (s *StreamHandler) AddOutput(out io.Writer)      { s.RegisterOutput(out) }
+func (s *StreamHandler) AddOutputs(outs ...io.Writer) {
+	for _, out := range outs {
+		s.RegisterOutput(out)
+	}
+}
+
+// SetFormatter replace formatter, safe to call while logging, messages output after it returns use f
+func (s *StreamHandler) SetFormatter(f Formatter) {
+	s.formatterMu.Lock()
+	defer s.formatterMu.Unlock()
+	s.Formatter = f
+}
 
 func (s *StreamHandler) Output(level Level, ctx context.Context, format string, v ...any) {
 	s.once.Do(func() { go s.serve() })
 	if s.allowLevel(level) {
-		s.ch <- []byte(fmt.Sprintf(s.Format(level, ctx, format), v...))
+		s.formatterMu.RLock()
+		f := s.Formatter
+		s.formatterMu.RUnlock()
+		msg := []byte(f.Format(level, ctx, fmt.Sprintf(format, v...)))
+		if !s.dropOnFull.Load() {
+			s.ch <- msg
+			return
+		}
+		select {
+		case s.ch <- msg:
+		default: // buffer full
+			s.dropped.Add(1)
+		}
 	}
 }
 
 func (s *StreamHandler) Write(p []byte) (int, error) { return s.out.Write(p) }
 
+// BufferSize return max count of messages buffered
+func (s *StreamHandler) BufferSize() int { return cap(s.ch) }
+
+// BufferUsed return count of messages buffered and waiting for output
+func (s *StreamHandler) BufferUsed() int { return len(s.ch) }
+
+// SetDropOnFull set whether to drop messages instead of blocking Output when buffer is full, default false
+func (s *StreamHandler) SetDropOnFull(drop bool) { s.dropOnFull.Store(drop) }
+
+// Dropped return count of messages dropped because of buffer full
+func (s *StreamHandler) Dropped() int64 { return s.dropped.Load() }
+
 func (s *StreamHandler) Flush() {
 	runtime.Gosched()
 	for i := cap(s.ch); i > 0; i-- { // max try times less than capacity of ch
@@ -72,6 +133,7 @@ func (s *StreamHandler) Flush() {
 }
 
 func (s *StreamHandler) Close() {
+	s.once.Do(func() { go s.serve() }) // serve close s.closed after ch drained
 	close(s.ch)
 	s.Flush()
 	<-s.closed
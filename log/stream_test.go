@@ -0,0 +1,181 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// syncBuffer buffer safe for concurrent use
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// waitFor wait until cond satisfied or timeout
+func waitFor(timeout time.Duration, cond func() bool) bool {
+	for deadline := time.Now().Add(timeout); time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+		if cond() {
+			return true
+		}
+	}
+	return cond()
+}
+
+// blockWriter block writing until release closed
+type blockWriter struct {
+	io.Writer
+	release chan struct{}
+}
+
+func (w *blockWriter) Write(p []byte) (int, error) {
+	<-w.release
+	return w.Writer.Write(p)
+}
+
+func TestStreamHandler_BufferSize(t *testing.T) {
+	if size := NewStreamHandler(InfoLevel).BufferSize(); size != 8*1024 {
+		t.Errorf("expect default buffer size 8192, got: %d", size)
+	}
+
+	var buf syncBuffer
+	out := &blockWriter{Writer: &buf, release: make(chan struct{})}
+
+	handler := NewStreamHandlerWithBufferSize(InfoLevel, 4)
+	handler.SetOutput(out)
+	if size := handler.BufferSize(); size != 4 {
+		t.Errorf("expect buffer size 4, got: %d", size)
+	}
+
+	handler.Output(InfoLevel, nil, "message 0") // taken by serve goroutine and blocked in writing
+	time.Sleep(10 * time.Millisecond)
+	for i := 1; i <= 3; i++ {
+		handler.Output(InfoLevel, nil, "message %d", i)
+	}
+	if used := handler.BufferUsed(); used != 3 {
+		t.Errorf("expect 3 buffered messages, got: %d", used)
+	}
+
+	close(out.release)
+	handler.Close()
+	if used := handler.BufferUsed(); used != 0 {
+		t.Errorf("expect empty buffer after close, got: %d", used)
+	}
+	if !waitFor(time.Second, func() bool { return strings.Count(buf.String(), "message") == 4 }) {
+		t.Errorf("expect 4 messages written, got: %q", buf.String())
+	}
+}
+
+func TestStreamHandler_drop(t *testing.T) {
+	var buf syncBuffer
+	out := &blockWriter{Writer: &buf, release: make(chan struct{})}
+
+	handler := NewStreamHandlerWithBufferSize(InfoLevel, 1)
+	handler.SetOutput(out)
+
+	handler.Output(InfoLevel, nil, "message 0") // taken by serve goroutine and blocked in writing
+	time.Sleep(10 * time.Millisecond)
+	handler.Output(InfoLevel, nil, "message 1")
+
+	// block by default
+	blocked := make(chan struct{})
+	go func() {
+		handler.Output(InfoLevel, nil, "blocked message")
+		close(blocked)
+	}()
+	select {
+	case <-blocked:
+		t.Fatal("expect Output blocked when buffer is full")
+	case <-time.After(20 * time.Millisecond):
+	}
+	close(out.release)
+	<-blocked
+	handler.Close()
+	if output := buf.String(); !strings.Contains(output, "blocked message") || handler.Dropped() != 0 {
+		t.Errorf("expect no message dropped by default, got: %q", output)
+	}
+
+	buf = syncBuffer{}
+	out = &blockWriter{Writer: &buf, release: make(chan struct{})}
+	handler = NewStreamHandlerWithBufferSize(InfoLevel, 1)
+	handler.SetOutput(out)
+	handler.SetDropOnFull(true)
+
+	handler.Output(InfoLevel, nil, "message 0")
+	time.Sleep(10 * time.Millisecond)
+	for i := 1; i <= 3; i++ {
+		handler.Output(InfoLevel, nil, "message %d", i)
+	}
+	if used := handler.BufferUsed(); used != 1 {
+		t.Errorf("expect 1 buffered message, got: %d", used)
+	}
+	if dropped := handler.Dropped(); dropped != 2 {
+		t.Errorf("expect 2 messages dropped, got: %d", dropped)
+	}
+
+	close(out.release)
+	handler.Close()
+	if output := buf.String(); !strings.Contains(output, "message 1") || strings.Contains(output, "message 3") {
+		t.Errorf("expect message 1 written and message 3 dropped, got: %q", output)
+	}
+}
+
+func TestStreamHandler_Close(t *testing.T) {
+	for _, size := range []int{-1, 0, 1} {
+		handler := NewStreamHandlerWithBufferSize(InfoLevel, size)
+		if size <= 0 && handler.BufferSize() != 8*1024 {
+			t.Errorf("expect default buffer size for %d, got: %d", size, handler.BufferSize())
+		}
+
+		done := make(chan struct{})
+		go func() {
+			handler.Close() // never output
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatalf("close handler with buffer size %d blocked", size)
+		}
+	}
+}
+
+func TestNewJSONStreamHandler(t *testing.T) {
+	var buf syncBuffer
+	handler := NewJSONStreamHandler(InfoLevel)
+	handler.SetOutput(&buf)
+
+	handler.Output(InfoLevel, nil, "json %q", "message")
+	handler.Flush()
+	handler.SetFormatter(NewStreamFormatter(false))
+	handler.Output(InfoLevel, nil, "text message")
+	handler.Close()
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expect 2 lines, got: %q", buf.String())
+	}
+	var entry map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil || entry["msg"] != `json "message"` {
+		t.Errorf("expect json line, got: %q, err: %v", lines[0], err)
+	}
+	if !strings.HasSuffix(lines[1], "[INFO] text message") {
+		t.Errorf("expect text line after formatter switched, got: %q", lines[1])
+	}
+}
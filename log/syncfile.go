@@ -0,0 +1,67 @@
+package log
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+var _ Handler = (*SyncFileHandler)(nil)
+
+// NewSyncFileHandler 同步写入的滚动文件日志，Output 返回时消息已写入文件，进程崩溃不会丢失日志
+// 适用于不允许丢失日志的场景如审计日志，吞吐量低于 FileHandler，不支持 FileHandlerLevelSplit
+func NewSyncFileHandler(level Level, dir string, opts ...FileHandlerOption) (*SyncFileHandler, error) {
+	f, err := NewFileHandler(level, dir, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if f.levelSplit {
+		f.Close()
+		return nil, errors.New("sync file handler does not support level split")
+	}
+	return &SyncFileHandler{FileHandler: f}, nil
+}
+
+// SyncFileHandler synchronous file handler, shares options and rotation with FileHandler
+type SyncFileHandler struct {
+	*FileHandler
+
+	mu     sync.Mutex // serialize writes, so messages are not interleaved
+	closed bool       // messages are dropped after closed
+}
+
+func (h *SyncFileHandler) Output(level Level, ctx context.Context, format string, v ...any) {
+	if !h.allowLevel(level) {
+		return
+	}
+	msg := []byte(h.Format(level, ctx, fmt.Sprintf(format, v...)))
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return
+	}
+	h.writeMsg(msg)
+}
+
+// Flush sync log file to disk
+func (h *SyncFileHandler) Flush() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return
+	}
+	h.flush()
+}
+
+// Close sync and close log file and its mirror, messages output after closed are dropped
+func (h *SyncFileHandler) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return
+	}
+	h.closed = true
+	h.closeFiles()
+}
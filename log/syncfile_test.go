@@ -0,0 +1,102 @@
+package log
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+)
+
+func TestSyncFileHandler(t *testing.T) {
+	handler, err := NewSyncFileHandler(InfoLevel, t.TempDir(), FileHandlerFormatter(NewStreamFormatter(false)))
+	if err != nil {
+		t.Fatalf("create sync file handler fail: %s", err)
+	}
+
+	handler.Output(DebugLevel, nil, "filtered")
+	handler.Output(InfoLevel, nil, "message %d", 0)
+	// written before Output returns, without Flush or Close
+	if data, _ := os.ReadFile(handler.FileName()); !bytes.HasSuffix(data, []byte("message 0\n")) || bytes.Contains(data, []byte("filtered")) {
+		t.Errorf("expect message written synchronously, got: %q", data)
+	}
+
+	const count = 100
+	var wg sync.WaitGroup
+	for i := 1; i <= count; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			handler.Output(InfoLevel, nil, "message %d", i)
+		}(i)
+	}
+	wg.Wait()
+	handler.Close()
+
+	data, _ := os.ReadFile(handler.FileName())
+	if lines := bytes.Count(data, []byte("\n")); lines != count+1 {
+		t.Errorf("expect %d lines, got %d", count+1, lines)
+	}
+	for i := 0; i <= count; i++ {
+		if !bytes.Contains(data, []byte(fmt.Sprintf("message %d\n", i))) {
+			t.Errorf("missing message %d", i)
+		}
+	}
+}
+
+func TestSyncFileHandler_options(t *testing.T) {
+	if _, err := NewSyncFileHandler(InfoLevel, t.TempDir(), FileHandlerLevelSplit()); err == nil {
+		t.Errorf("expect level split rejected")
+	}
+
+	dir, mirrorDir := t.TempDir(), t.TempDir()
+	handler, err := NewSyncFileHandler(InfoLevel, dir, FileHandlerMirrorPath(mirrorDir))
+	if err != nil {
+		t.Fatalf("create sync file handler fail: %s", err)
+	}
+	handler.Output(InfoLevel, nil, "mirrored")
+	mirror := handler.mirror
+	if mirror == nil {
+		t.Fatal("expect mirror file opened")
+	}
+	handler.Close()
+	if _, err := mirror.Write([]byte("after close")); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("expect mirror file closed, got: %v", err)
+	}
+
+	// output and close after closed are no-op, log file is not reopened
+	out := handler.out
+	handler.Output(InfoLevel, nil, "after close")
+	handler.Close()
+	if handler.out != out {
+		t.Errorf("expect log file not reopened after close")
+	}
+	if data, _ := os.ReadFile(handler.FileName()); bytes.Contains(data, []byte("after close")) {
+		t.Errorf("expect message dropped after close, got: %q", data)
+	}
+}
+
+func BenchmarkFileHandler_Output(b *testing.B) {
+	handler, err := NewFileHandler(InfoLevel, b.TempDir())
+	if err != nil {
+		b.Fatalf("create file handler fail: %s", err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		handler.Output(InfoLevel, nil, "this is a log handler benchmark test string %d", i)
+	}
+	handler.Close()
+}
+
+func BenchmarkSyncFileHandler_Output(b *testing.B) {
+	handler, err := NewSyncFileHandler(InfoLevel, b.TempDir())
+	if err != nil {
+		b.Fatalf("create sync file handler fail: %s", err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		handler.Output(InfoLevel, nil, "this is a log handler benchmark test string %d", i)
+	}
+	handler.Close()
+}
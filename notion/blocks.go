@@ -2,8 +2,18 @@ package notion
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
 
 	"golang.org/x/time/rate"
+
+	"github.com/tr1v3r/pkg/fetch"
+	"github.com/tr1v3r/pkg/log"
 )
 
 // NewBlockManager return a new database manager
@@ -19,6 +29,8 @@ type BlockManager struct {
 	*baseInfo
 
 	ctx     context.Context
+	timeout time.Duration
+	baseURL string // notion api url, default one if empty
 	id      string
 	limiter *rate.Limiter
 }
@@ -29,6 +41,18 @@ func (bm BlockManager) WithContext(ctx context.Context) *BlockManager {
 	return &bm
 }
 
+// WithTimeout set timeout for each request, zero means no timeout
+func (bm BlockManager) WithTimeout(timeout time.Duration) *BlockManager {
+	bm.timeout = timeout
+	return &bm
+}
+
+// WithBaseURL set notion api url, e.g. https://api.notion.com/v1, empty means default one
+func (bm BlockManager) WithBaseURL(baseURL string) *BlockManager {
+	bm.baseURL = baseURL
+	return &bm
+}
+
 // WithID set block id
 func (bm BlockManager) WithID(id string) *BlockManager {
 	bm.id = id
@@ -45,3 +69,165 @@ func (bm BlockManager) WithLimiter(limiter *rate.Limiter) *BlockManager {
 func (bm *BlockManager) ID() string {
 	return bm.id
 }
+
+// Block notion block
+// docs: https://developers.notion.com/reference/block
+type Block struct {
+	PureObject
+	Type           string `json:"type"`
+	HasChildren    bool   `json:"has_children"`
+	CreatedTime    string `json:"created_time,omitempty"`
+	LastEditedTime string `json:"last_edited_time,omitempty"`
+	Archived       bool   `json:"archived,omitempty"`
+
+	// Content type specific content, e.g. value of field "paragraph" for paragraph block
+	Content json.RawMessage `json:"-"`
+}
+
+// UnmarshalJSON unmarshal block and keep type specific content
+func (b *Block) UnmarshalJSON(data []byte) error {
+	type block Block
+	if err := json.Unmarshal(data, (*block)(b)); err != nil {
+		return err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	b.Content = fields[b.Type]
+	return nil
+}
+
+// BlockTree block with all its children
+type BlockTree struct {
+	Block    Block
+	Children []BlockTree
+}
+
+// GetAllChildren retrieve children of block recursively, up to maxDepth levels, 0 means unlimited
+// children of blocks in same level are retrieved concurrently
+func (bm *BlockManager) GetAllChildren(blockID string, maxDepth int) ([]BlockTree, error) {
+	blocks, err := bm.listChildren(blockID)
+	if err != nil {
+		return nil, err
+	}
+	trees := newBlockTrees(blocks)
+
+	level := make([]*BlockTree, 0, len(trees))
+	for i := range trees {
+		level = append(level, &trees[i])
+	}
+	for depth := 1; len(level) > 0 && (maxDepth <= 0 || depth < maxDepth); depth++ {
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+		var firstErr error
+		for _, parent := range level {
+			if !parent.Block.HasChildren {
+				continue
+			}
+
+			wg.Add(1)
+			go func(parent *BlockTree) {
+				defer wg.Done()
+				blocks, err := bm.listChildren(parent.Block.ID)
+				if err != nil {
+					mu.Lock()
+					if firstErr == nil {
+						firstErr = err
+					}
+					mu.Unlock()
+					return
+				}
+				parent.Children = newBlockTrees(blocks)
+			}(parent)
+		}
+		wg.Wait()
+		if firstErr != nil {
+			return nil, firstErr
+		}
+
+		var next []*BlockTree
+		for _, parent := range level {
+			for i := range parent.Children {
+				next = append(next, &parent.Children[i])
+			}
+		}
+		level = next
+	}
+	return trees, nil
+}
+
+func newBlockTrees(blocks []Block) []BlockTree {
+	trees := make([]BlockTree, len(blocks))
+	for i, block := range blocks {
+		trees[i].Block = block
+	}
+	return trees
+}
+
+// listChildren retrieve all direct children of block
+// docs: https://developers.notion.com/reference/get-block-children
+func (bm *BlockManager) listChildren(blockID string) ([]Block, error) {
+	const pageSize = 100
+
+	log.CtxDebug(bm.ctx, "retrieve block %s children", blockID)
+
+	param := url.Values{}
+	param.Set("page_size", strconv.Itoa(pageSize))
+
+	var blocks []Block
+	for hasMore := true; hasMore; {
+		_ = bm.limiter.Wait(bm.ctx)
+		_, resp, err := retryOn429(bm.ctx, func() (int, []byte, http.Header, error) {
+			return fetch.DoRequestWithOptions(http.MethodGet, bm.WithID(blockID).api(childrenOp)+"?"+param.Encode(),
+				append(bm.Headers(), requestOptions(bm.ctx, bm.timeout)...), nil)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("request api fail: %w", err)
+		}
+		log.CtxDebug(bm.ctx, "retrieve block children got response %s", string(resp))
+
+		var result struct {
+			PureObject
+			Results    []Block `json:"results"`
+			NextCursor string  `json:"next_cursor"`
+			HasMore    bool    `json:"has_more"`
+
+			Status  int    `json:"status,omitempty"`
+			Code    string `json:"code,omitempty"`
+			Message string `json:"message,omitempty"`
+		}
+		if err := json.Unmarshal(resp, &result); err != nil {
+			return nil, fmt.Errorf("unmarshal response fail: %w", err)
+		}
+		if result.Object == "error" {
+			switch result.Status {
+			case 404:
+				return nil, ErrNotFound
+			case 429:
+				return nil, ErrRateLimited
+			default:
+				return nil, fmt.Errorf("response error: [%d / %s] %s", result.Status, result.Code, result.Message)
+			}
+		}
+
+		blocks = append(blocks, result.Results...)
+		hasMore = result.HasMore && result.NextCursor != ""
+		param.Set("start_cursor", result.NextCursor)
+	}
+	return blocks, nil
+}
+
+// api return block api
+func (bm *BlockManager) api(typ operateType) string {
+	baseAPI := notionAPI(bm.baseURL) + "/blocks"
+	switch typ {
+	case retrieveOp: // GET https://api.notion.com/v1/blocks/{block_id}
+		return baseAPI + "/" + bm.id
+	case childrenOp: // GET https://api.notion.com/v1/blocks/{block_id}/children
+		return baseAPI + "/" + bm.id + "/children"
+	default:
+		return ""
+	}
+}
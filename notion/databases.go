@@ -3,9 +3,12 @@ package notion
 import (
 	"bytes"
 	"context"
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"io"
+	"net/http"
+	"time"
 
 	"golang.org/x/time/rate"
 
@@ -26,6 +29,8 @@ type DatabaseManager struct {
 	*baseInfo
 
 	ctx     context.Context
+	timeout time.Duration
+	baseURL string // notion api url, default one if empty
 	id      string
 	limiter *rate.Limiter
 }
@@ -36,6 +41,18 @@ func (dm DatabaseManager) WithContext(ctx context.Context) *DatabaseManager {
 	return &dm
 }
 
+// WithTimeout set timeout for each request, zero means no timeout
+func (dm DatabaseManager) WithTimeout(timeout time.Duration) *DatabaseManager {
+	dm.timeout = timeout
+	return &dm
+}
+
+// WithBaseURL set notion api url, e.g. https://api.notion.com/v1, empty means default one
+func (dm DatabaseManager) WithBaseURL(baseURL string) *DatabaseManager {
+	dm.baseURL = baseURL
+	return &dm
+}
+
 // WithID set database id
 func (dm DatabaseManager) WithID(id string) *DatabaseManager {
 	dm.id = id
@@ -72,8 +89,10 @@ func (dm *DatabaseManager) Create(parent PageItem, title []TextObject, propertie
 	})
 
 	_ = dm.limiter.Wait(dm.ctx)
-	statusCode, resp, _, err := fetch.DoRequestWithOptions("POST", dm.api(createOp),
-		append(dm.Headers(), fetch.WithContext(dm.ctx)), bytes.NewReader(payload))
+	statusCode, resp, err := retryOn429(dm.ctx, func() (int, []byte, http.Header, error) {
+		return fetch.DoRequestWithOptions(http.MethodPost, dm.api(createOp),
+			append(dm.Headers(), requestOptions(dm.ctx, dm.timeout)...), bytes.NewReader(payload))
+	})
 	if err != nil {
 		return fmt.Errorf("create database fail: %w", err)
 	}
@@ -93,7 +112,9 @@ func (dm *DatabaseManager) Retrieve() (*Object, error) {
 	log.CtxDebug(dm.ctx, "retrieve database %s", dm.id)
 
 	_ = dm.limiter.Wait(dm.ctx)
-	resp, err := fetch.CtxGet(dm.ctx, dm.api(retrieveOp), dm.Headers()...)
+	_, resp, err := retryOn429(dm.ctx, func() (int, []byte, http.Header, error) {
+		return fetch.DoRequestWithOptions(http.MethodGet, dm.api(retrieveOp), append(dm.Headers(), requestOptions(dm.ctx, dm.timeout)...), nil)
+	})
 	if err != nil {
 		return nil, fmt.Errorf("retrieve database %s fail: %w", dm.id, err)
 	}
@@ -165,13 +186,16 @@ func (dm *DatabaseManager) asyncQuery(cond *Condition) (<-chan Object, <-chan er
 		// defer close(errCh)
 		defer close(ch)
 		var count int
-		var api = dm.api(queryOp) + "?" + cond.QueryParams()
+		var api = dm.api(queryOp) + "?" + cond.filterParams().Encode()
 
 		var obj = new(Object)
 		for obj.HasMore = true; obj.HasMore; {
 			cond.StartCursor = obj.NextCursor
 			_ = dm.limiter.Wait(dm.ctx)
-			resp, err := fetch.CtxPost(dm.ctx, api, bytes.NewReader(cond.Payload()), dm.Headers()...)
+			_, resp, err := retryOn429(dm.ctx, func() (int, []byte, http.Header, error) {
+				return fetch.DoRequestWithOptions(http.MethodPost, api,
+					append(dm.Headers(), requestOptions(dm.ctx, dm.timeout)...), bytes.NewReader(cond.Payload()))
+			})
 			if err != nil {
 				// log.CtxError(dm.ctx, "retrieve database %s fail: %s", dm.id, err)
 				errCh <- fmt.Errorf("retrieve database %s fail: %w", dm.id, err)
@@ -204,14 +228,117 @@ func (dm *DatabaseManager) asyncQuery(cond *Condition) (<-chan Object, <-chan er
 	return ch, errCh
 }
 
+// Watch poll database every interval until ctx done, send objects added or modified since last poll,
+// modification is detected by comparing last_edited_time, the first send contains all objects as baseline.
+// deleted objects are not detectable because query api does not return them.
+// errors of polls are sent to error channel without stopping watching, both channels are closed when ctx done.
+// non-positive interval is sent as the only error, with both channels closed
+func (dm *DatabaseManager) Watch(ctx context.Context, cond *Condition, interval time.Duration) (<-chan []Object, <-chan error) {
+	ch := make(chan []Object)
+	if interval <= 0 {
+		errCh := make(chan error, 1)
+		errCh <- fmt.Errorf("invalid watch interval: %s", interval)
+		close(ch)
+		close(errCh)
+		return ch, errCh
+	}
+	errCh := make(chan error)
+
+	go func() {
+		defer close(ch)
+		defer close(errCh)
+
+		mgr := dm.WithContext(ctx)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		var seen map[string]string // id -> last edited time
+		for {
+			objects, err := mgr.Query(cond)
+			if err != nil {
+				select {
+				case errCh <- err:
+				case <-ctx.Done():
+					return
+				}
+			} else if changed := diffObjects(seen, objects); seen == nil || len(changed) > 0 {
+				select {
+				case ch <- changed:
+				case <-ctx.Done():
+					return
+				}
+				if seen == nil {
+					seen = make(map[string]string, len(objects))
+				}
+				for _, obj := range changed {
+					seen[obj.ID] = obj.LastEditedTime
+				}
+			}
+
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch, errCh
+}
+
+// diffObjects return objects not in seen or edited since seen
+func diffObjects(seen map[string]string, objects []Object) []Object {
+	changed := make([]Object, 0, len(objects))
+	for _, obj := range objects {
+		if editedTime, ok := seen[obj.ID]; !ok || editedTime != obj.LastEditedTime {
+			changed = append(changed, obj)
+		}
+	}
+	return changed
+}
+
+// ExportCSV query database and write properties named in columns as csv, with header line
+func (dm *DatabaseManager) ExportCSV(cond *Condition, w io.Writer, columns []string) error {
+	objects, err := dm.Query(cond)
+	if err != nil {
+		return err
+	}
+
+	writer := csv.NewWriter(w)
+	writer.UseCRLF = true // RFC 4180
+	if err := writer.Write(columns); err != nil {
+		return fmt.Errorf("write csv header fail: %w", err)
+	}
+
+	row := make([]string, len(columns))
+	for _, obj := range objects {
+		for i, column := range columns {
+			row[i] = obj.Properties[column].PlainValue()
+		}
+		if err := writer.Write(row); err != nil {
+			return fmt.Errorf("write csv row fail: %w", err)
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
 // Update update database
 // docs: https://developers.notion.com/reference/update-a-database
 // PATCH https://api.notion.com/v1/databases/{database_id}
 func (dm *DatabaseManager) Update(payload io.Reader) error {
 	log.CtxDebug(dm.ctx, "update database %s", dm.id)
 
+	data, err := io.ReadAll(payload)
+	if err != nil {
+		return fmt.Errorf("read payload fail: %w", err)
+	}
+
 	_ = dm.limiter.Wait(dm.ctx)
-	resp, err := fetch.CtxPatch(dm.ctx, dm.api(updateOp), payload, dm.Headers()...)
+	_, resp, err := retryOn429(dm.ctx, func() (int, []byte, http.Header, error) {
+		return fetch.DoRequestWithOptions(http.MethodPatch, dm.api(updateOp),
+			append(dm.Headers(), requestOptions(dm.ctx, dm.timeout)...), bytes.NewReader(data))
+	})
 	if err != nil {
 		return fmt.Errorf("query api %s fail: %w", dm.id, err)
 	}
@@ -233,7 +360,7 @@ func (dm *DatabaseManager) Update(payload io.Reader) error {
 
 // api return database api
 func (dm *DatabaseManager) api(typ operateType) string {
-	baseAPI := notionAPI() + "/databases"
+	baseAPI := notionAPI(dm.baseURL) + "/databases"
 	switch typ {
 	case createOp: // POST https://api.notion.com/v1/databases
 		return baseAPI
@@ -6,4 +6,7 @@ var (
 	// ErrRateLimited rate limited
 	// https://developers.notion.com/reference/request-limits
 	ErrRateLimited = errors.New("notion rate limited")
+
+	// ErrNotFound object not found
+	ErrNotFound = errors.New("notion object not found")
 )
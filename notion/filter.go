@@ -3,6 +3,7 @@ package notion
 import (
 	"encoding/json"
 	"net/url"
+	"strconv"
 )
 
 // Condition query filter
@@ -15,8 +16,30 @@ type Condition struct {
 	Sorts       []PropSortCondition `json:"sorts,omitempty"`
 }
 
+// QueryParams return url encoded filter_properties, page_size and start_cursor, empty if none set
 func (f *Condition) QueryParams() string {
-	return url.Values{"filter_properties": f.FilterProperties}.Encode()
+	if f == nil {
+		return ""
+	}
+
+	params := f.filterParams()
+	if f.PageSize > 0 {
+		params.Set("page_size", strconv.Itoa(f.PageSize))
+	}
+	if f.StartCursor != "" {
+		params.Set("start_cursor", f.StartCursor)
+	}
+	return params.Encode()
+}
+
+// filterParams return query params only limiting properties returned,
+// used by POST query whose pagination is carried in payload
+func (f *Condition) filterParams() url.Values {
+	params := url.Values{}
+	for _, prop := range f.FilterProperties {
+		params.Add("filter_properties", prop)
+	}
+	return params
 }
 
 // Payload return payload
@@ -208,6 +231,43 @@ type RollupFilter struct {
 	None  map[string]any `json:"none,omitempty"`
 }
 
+// NewRollupFilter return builder of rollup filter
+func NewRollupFilter() *RollupFilterBuilder { return &RollupFilterBuilder{} }
+
+// RollupFilterBuilder build RollupFilter from typed filters like *NumberFilter
+type RollupFilterBuilder struct {
+	filter RollupFilter
+}
+
+// Any match rollup with any item matching filter of propertyType like number, rich_text
+func (b *RollupFilterBuilder) Any(propertyType PropertyType, filter any) *RollupFilterBuilder {
+	b.filter.Any = setRollupItem(b.filter.Any, propertyType, filter)
+	return b
+}
+
+// Every match rollup with every item matching filter of propertyType
+func (b *RollupFilterBuilder) Every(propertyType PropertyType, filter any) *RollupFilterBuilder {
+	b.filter.Every = setRollupItem(b.filter.Every, propertyType, filter)
+	return b
+}
+
+// None match rollup with no item matching filter of propertyType
+func (b *RollupFilterBuilder) None(propertyType PropertyType, filter any) *RollupFilterBuilder {
+	b.filter.None = setRollupItem(b.filter.None, propertyType, filter)
+	return b
+}
+
+// Build return rollup filter built
+func (b *RollupFilterBuilder) Build() RollupFilter { return b.filter }
+
+func setRollupItem(item map[string]any, propertyType PropertyType, filter any) map[string]any {
+	if item == nil {
+		item = make(map[string]any)
+	}
+	item[string(propertyType)] = filter
+	return item
+}
+
 // StatusFilter ...
 type StatusFilter struct {
 	Equals       string `json:"equals,omitempty"`
@@ -227,7 +287,33 @@ type TimestampFilter struct {
 // https://developers.notion.com/reference/post-database-query-filter#id
 type IDFilter map[any][]NumberFilter
 
+// PropSortCondition sort by property or timestamp
+// https://developers.notion.com/reference/post-database-query-sort
 type PropSortCondition struct {
-	Property  string `json:"property"`
-	Direction string `json:"direction"` // "ascending" or "descending"
+	Property  string `json:"property,omitempty"`
+	Timestamp string `json:"timestamp,omitempty"` // "created_time" or "last_edited_time"
+	Direction string `json:"direction"`           // "ascending" or "descending"
+}
+
+// SortDirection sort direction
+type SortDirection string
+
+const (
+	Ascending  SortDirection = "ascending"
+	Descending SortDirection = "descending"
+)
+
+// SortAscending sort by property in ascending order
+func SortAscending(property string) PropSortCondition {
+	return PropSortCondition{Property: property, Direction: string(Ascending)}
+}
+
+// SortDescending sort by property in descending order
+func SortDescending(property string) PropSortCondition {
+	return PropSortCondition{Property: property, Direction: string(Descending)}
+}
+
+// TimestampSort sort by timestamp created_time or last_edited_time
+func TimestampSort(timestamp, direction string) PropSortCondition {
+	return PropSortCondition{Timestamp: timestamp, Direction: direction}
 }
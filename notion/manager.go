@@ -3,6 +3,10 @@ package notion
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"golang.org/x/time/rate"
 
@@ -24,10 +28,14 @@ const (
 	retrieveOp     operateType = "retreive"
 	retrievePropOp operateType = "retrieveProp"
 	updateOp       operateType = "update"
+	childrenOp     operateType = "children"
 )
 
-// notionAPI return notion api url
-func notionAPI() string {
+// notionAPI return notion api url, baseURL replaces default one if not empty
+func notionAPI(baseURL string) string {
+	if baseURL != "" {
+		return strings.TrimSuffix(baseURL, "/")
+	}
 	return fmt.Sprintf("%s://%s/%s", notionAPIHostScheme, notionAPIHost, apiBasePath)
 }
 
@@ -74,6 +82,27 @@ func (mgr Manager) WithContext(ctx context.Context) *Manager {
 	return &mgr
 }
 
+// WithTimeout set timeout for notion manager, timeout applies to each request separately
+func (mgr Manager) WithTimeout(timeout time.Duration) *Manager {
+	mgr.DatabaseManager = mgr.DatabaseManager.WithTimeout(timeout)
+	mgr.PageManager = mgr.PageManager.WithTimeout(timeout)
+	mgr.BlockManager = mgr.BlockManager.WithTimeout(timeout)
+	mgr.SearchManager = mgr.SearchManager.WithTimeout(timeout)
+	return &mgr
+}
+
+// WithBaseURL set notion api url for notion manager, e.g. https://api.notion.com/v1, empty means default one
+func (mgr Manager) WithBaseURL(baseURL string) *Manager {
+	mgr.DatabaseManager = mgr.DatabaseManager.WithBaseURL(baseURL)
+	mgr.PageManager = mgr.PageManager.WithBaseURL(baseURL)
+	mgr.BlockManager = mgr.BlockManager.WithBaseURL(baseURL)
+	mgr.SearchManager = mgr.SearchManager.WithBaseURL(baseURL)
+	return &mgr
+}
+
+// Context return context of notion manager
+func (mgr *Manager) Context() context.Context { return mgr.DatabaseManager.ctx }
+
 // WithLimiter set limiter for notion manager
 func (mgr Manager) WithLimiter(limiter *rate.Limiter) *Manager {
 	mgr.DatabaseManager = mgr.DatabaseManager.WithLimiter(limiter)
@@ -83,6 +112,60 @@ func (mgr Manager) WithLimiter(limiter *rate.Limiter) *Manager {
 	return &mgr
 }
 
+// Database return database manager for database id
+func (mgr *Manager) Database(id string) *DatabaseManager { return mgr.DatabaseManager.WithID(id) }
+
+// Page return page manager for page id
+func (mgr *Manager) Page(id string) *PageManager { return mgr.PageManager.WithID(id) }
+
+// Block return block manager for block id
+func (mgr *Manager) Block(id string) *BlockManager { return mgr.BlockManager.WithID(id) }
+
+// defaultRetryAfter wait duration before retry when 429 response has no valid Retry-After header
+const defaultRetryAfter = time.Second
+
+// retryOn429 call fn, retry once after duration in Retry-After header if notion responds 429
+func retryOn429(ctx context.Context, fn func() (int, []byte, http.Header, error)) (int, []byte, error) {
+	statusCode, resp, headers, err := fn()
+	if err != nil || statusCode != http.StatusTooManyRequests {
+		return statusCode, resp, err
+	}
+
+	timer := time.NewTimer(retryAfter(headers))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+		return statusCode, resp, ctx.Err()
+	}
+
+	statusCode, resp, _, err = fn()
+	return statusCode, resp, err
+}
+
+// retryAfter parse Retry-After header in seconds or http date
+func retryAfter(headers http.Header) time.Duration {
+	value := headers.Get("Retry-After")
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		if wait := time.Until(date); wait > 0 {
+			return wait
+		}
+		return 0
+	}
+	return defaultRetryAfter
+}
+
+// requestOptions return options carrying ctx, each request is limited to timeout if timeout is positive
+func requestOptions(ctx context.Context, timeout time.Duration) []fetch.RequestOption {
+	if timeout > 0 {
+		return []fetch.RequestOption{fetch.WithContext(ctx), fetch.WithTimeout(timeout)}
+	}
+	return []fetch.RequestOption{fetch.WithContext(ctx)}
+}
+
 type baseInfo struct {
 	NotionVersion string
 	BearerToken   string
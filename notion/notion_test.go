@@ -1,9 +1,22 @@
 package notion
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
+
+	"golang.org/x/time/rate"
 
 	"github.com/tr1v3r/pkg/log"
 )
@@ -68,3 +81,593 @@ func TestCreate_Page(t *testing.T) {
 		t.Errorf("create page fail: %s", err)
 	}
 }
+
+func TestManager_accessors(t *testing.T) {
+	ctx := context.WithValue(context.Background(), "log_id", "test")
+	mgr := NewManager(version, token).WithContext(ctx)
+
+	db := mgr.Database("db-id")
+	if api := db.api(retrieveOp); api != notionAPI("")+"/databases/db-id" {
+		t.Errorf("unexpected database api: %s", api)
+	}
+	if db.limiter != mgr.DatabaseManager.limiter || db.ctx != ctx {
+		t.Errorf("database manager should share limiter and context with manager")
+	}
+
+	page := mgr.Page("page-id")
+	if api := page.api(retrieveOp); api != notionAPI("")+"/pages/page-id" {
+		t.Errorf("unexpected page api: %s", api)
+	}
+	if page.limiter != mgr.PageManager.limiter || page.ctx != ctx {
+		t.Errorf("page manager should share limiter and context with manager")
+	}
+
+	block := mgr.Block("block-id")
+	if block.ID() != "block-id" || block.limiter != mgr.BlockManager.limiter || block.ctx != ctx {
+		t.Errorf("unexpected block manager: %+v", block)
+	}
+
+	if mgr.DatabaseManager.ID() != "" {
+		t.Errorf("accessor should not modify manager")
+	}
+}
+
+// newMockServer start a server serving in place of notion api, return its api url
+func newMockServer(t *testing.T, handler http.HandlerFunc) (baseURL string) {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return server.URL + "/" + apiBasePath
+}
+
+func TestDatabaseManager_ExportCSV(t *testing.T) {
+	baseURL := newMockServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/v1/databases/db-id/query" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		_, _ = w.Write([]byte(`{"object":"list","has_more":false,"results":[
+			{"object":"page","id":"1","properties":{
+				"Name":{"type":"title","title":[{"plain_text":"Apple, Inc."}]},
+				"Price":{"type":"number","number":182.5},
+				"Date":{"type":"date","date":{"start":"2024-01-01"}},
+				"Done":{"type":"checkbox","checkbox":true},
+				"Note":{"type":"rich_text","rich_text":[{"plain_text":"say \"hi\"\nbye"}]}}},
+			{"object":"page","id":"2","properties":{
+				"Name":{"type":"title","title":[{"plain_text":"Banana"}]},
+				"Price":{"type":"number","number":null},
+				"Done":{"type":"checkbox","checkbox":false}}}
+		]}`))
+	})
+
+	var buf bytes.Buffer
+	err := NewDatabaseManager(version, token).WithBaseURL(baseURL).WithID("db-id").ExportCSV(nil, &buf, []string{"Name", "Price", "Date", "Done", "Note"})
+	if err != nil {
+		t.Fatalf("export csv fail: %s", err)
+	}
+
+	expected := "Name,Price,Date,Done,Note\r\n" +
+		"\"Apple, Inc.\",182.5,2024-01-01,true,\"say \"\"hi\"\"\r\nbye\"\r\n" +
+		"Banana,,,false,\r\n"
+	if buf.String() != expected {
+		t.Errorf("unexpected csv:\n%q\nexpect:\n%q", buf.String(), expected)
+	}
+}
+
+func TestPageManager_typedGetters(t *testing.T) {
+	props := map[string]string{
+		"title":    `{"object":"list","results":[{"object":"property_item","type":"title","title":{"plain_text":"Hello "}},{"object":"property_item","type":"title","title":{"plain_text":"world"}}],"has_more":false,"property_item":{"id":"title","type":"title"}}`,
+		"number":   `{"object":"property_item","type":"number","number":42.5}`,
+		"date":     `{"object":"property_item","type":"date","date":{"start":"2023-01-01","end":"2023-01-02"}}`,
+		"checkbox": `{"object":"property_item","type":"checkbox","checkbox":true}`,
+		"select":   `{"object":"property_item","type":"select","select":{"name":"done"}}`,
+	}
+	baseURL := newMockServer(t, func(w http.ResponseWriter, r *http.Request) {
+		prop, ok := props[strings.TrimPrefix(r.URL.Path, "/v1/pages/page-id/properties/")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			prop = `{"object":"error","status":404,"code":"object_not_found","message":"not found"}`
+		}
+		_, _ = w.Write([]byte(prop))
+	})
+
+	page := NewManager(version, token).WithBaseURL(baseURL).Page("page-id")
+	if text, err := page.GetText("title"); err != nil || text != "Hello world" {
+		t.Errorf("unexpected text: %q, err: %v", text, err)
+	}
+	if number, err := page.GetNumber("number"); err != nil || number != 42.5 {
+		t.Errorf("unexpected number: %v, err: %v", number, err)
+	}
+	if date, err := page.GetDate("date"); err != nil || date == nil || date.Start != "2023-01-01" || date.End != "2023-01-02" {
+		t.Errorf("unexpected date: %+v, err: %v", date, err)
+	}
+	if checked, err := page.GetCheckbox("checkbox"); err != nil || !checked {
+		t.Errorf("unexpected checkbox: %v, err: %v", checked, err)
+	}
+	if name, err := page.GetSelect("select"); err != nil || name != "done" {
+		t.Errorf("unexpected select: %q, err: %v", name, err)
+	}
+
+	if _, err := page.GetNumber("checkbox"); err == nil {
+		t.Errorf("expect type mismatch error")
+	}
+	if _, err := page.GetText("missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expect ErrNotFound, got: %v", err)
+	}
+}
+
+func TestProperty_UniqueIDAndEmail(t *testing.T) {
+	for _, testcase := range []struct {
+		data      string
+		plainText string
+		forUpdate string
+	}{
+		{`{"id":"a","name":"ID","type":"unique_id","unique_id":{"number":3,"prefix":"TASK"}}`, "TASK-3", ""},
+		{`{"id":"b","name":"No","type":"unique_id","unique_id":{"number":7,"prefix":null}}`, "7", ""},
+		{`{"id":"c","name":"Mail","type":"email","email":"foo@example.com"}`, "foo@example.com", `{"email":"foo@example.com"}`},
+	} {
+		var prop Property
+		if err := json.Unmarshal([]byte(testcase.data), &prop); err != nil {
+			t.Errorf("unmarshal property fail: %s", err)
+			continue
+		}
+		if text := prop.PlainText(); text != testcase.plainText {
+			t.Errorf("unexpected plain text: %q, expect: %q", text, testcase.plainText)
+		}
+		if data := prop.ForUpdate(); string(data) != testcase.forUpdate {
+			t.Errorf("unexpected update data: %s, expect: %s", data, testcase.forUpdate)
+		}
+
+		data, err := json.Marshal(prop)
+		if err != nil {
+			t.Errorf("marshal property fail: %s", err)
+			continue
+		}
+		if string(data) != testcase.data {
+			t.Errorf("property round trip mismatch: %s, expect: %s", data, testcase.data)
+		}
+	}
+}
+
+func TestBlockManager_GetAllChildren(t *testing.T) {
+	// block id -> children ids
+	children := map[string][]string{
+		"root": {"a", "b"},
+		"a":    {"a1", "a2"},
+		"a1":   {"a1x"},
+		"b":    {},
+	}
+	baseURL := newMockServer(t, func(w http.ResponseWriter, r *http.Request) {
+		id := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/v1/blocks/"), "/children")
+		var results []string
+		for _, child := range children[id] {
+			_, hasChildren := children[child]
+			results = append(results, fmt.Sprintf(`{"object":"block","id":%q,"type":"paragraph","has_children":%t,"paragraph":{"rich_text":[]}}`, child, hasChildren))
+		}
+		_, _ = fmt.Fprintf(w, `{"object":"list","results":[%s],"has_more":false}`, strings.Join(results, ","))
+	})
+
+	var dump func(trees []BlockTree) string
+	dump = func(trees []BlockTree) string {
+		var s []string
+		for _, tree := range trees {
+			if len(tree.Children) > 0 {
+				s = append(s, tree.Block.ID+"("+dump(tree.Children)+")")
+			} else {
+				s = append(s, tree.Block.ID)
+			}
+		}
+		return strings.Join(s, ",")
+	}
+
+	mgr := NewManager(version, token).WithBaseURL(baseURL)
+	for _, testcase := range []struct {
+		maxDepth int
+		expected string
+	}{
+		{0, "a(a1(a1x),a2),b"},
+		{1, "a,b"},
+		{2, "a(a1,a2),b"},
+	} {
+		trees, err := mgr.GetAllChildren("root", testcase.maxDepth)
+		if err != nil {
+			t.Errorf("get all children fail: %s", err)
+			continue
+		}
+		if tree := dump(trees); tree != testcase.expected {
+			t.Errorf("depth %d: unexpected tree: %s, expect: %s", testcase.maxDepth, tree, testcase.expected)
+		}
+		if string(trees[0].Block.Content) != `{"rich_text":[]}` {
+			t.Errorf("unexpected block content: %s", trees[0].Block.Content)
+		}
+	}
+}
+
+func TestDatabaseManager_Watch(t *testing.T) {
+	polls := []string{
+		`{"id":"a","last_edited_time":"t1"},{"id":"b","last_edited_time":"t1"}`,
+		`{"id":"a","last_edited_time":"t2"},{"id":"b","last_edited_time":"t1"},{"id":"c","last_edited_time":"t1"}`,
+	}
+	var mu sync.Mutex
+	var poll int
+	baseURL := newMockServer(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		results := polls[len(polls)-1]
+		if poll < len(polls) {
+			results = polls[poll]
+		}
+		poll++
+		mu.Unlock()
+		_, _ = fmt.Fprintf(w, `{"object":"list","has_more":false,"results":[%s]}`, results)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ch, errCh := NewManager(version, token).WithBaseURL(baseURL).Database("db-id").Watch(ctx, nil, 10*time.Millisecond)
+
+	ids := func(objects []Object) (ids []string) {
+		for _, obj := range objects {
+			ids = append(ids, obj.ID)
+		}
+		return ids
+	}
+	for _, expected := range []string{"a b", "a c"} {
+		select {
+		case objects := <-ch:
+			if got := strings.Join(ids(objects), " "); got != expected {
+				t.Errorf("unexpected changed objects: %s, expect: %s", got, expected)
+			}
+		case err := <-errCh:
+			t.Fatalf("watch fail: %s", err)
+		case <-time.After(time.Second):
+			t.Fatalf("timeout waiting for changes %s", expected)
+		}
+	}
+
+	select {
+	case objects := <-ch:
+		t.Errorf("unchanged objects should not be sent, got: %v", ids(objects))
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	cancel()
+	for range ch {
+	}
+
+	ch, errCh = NewManager(version, token).WithBaseURL(baseURL).Database("db-id").Watch(context.Background(), nil, 0)
+	if err := <-errCh; err == nil {
+		t.Errorf("expect error with invalid interval")
+	}
+	if _, ok := <-ch; ok {
+		t.Errorf("expect channel closed with invalid interval")
+	}
+}
+
+func TestObject_getters(t *testing.T) {
+	var obj Object
+	if err := json.Unmarshal([]byte(`{"object":"page","id":"1","properties":{
+		"Name":{"id":"title","type":"title","title":[{"plain_text":"Hello"},{"plain_text":" world"}]},
+		"Note":{"id":"n","type":"rich_text","rich_text":[{"plain_text":"note"}]},
+		"Count":{"id":"c","type":"number","number":3.5},
+		"Empty":{"id":"e","type":"number","number":null},
+		"Due":{"id":"d","type":"date","date":{"start":"2023-01-01"}},
+		"NoDue":{"id":"nd","type":"date","date":null},
+		"Done":{"id":"x","type":"checkbox","checkbox":false},
+		"Status":{"id":"s","type":"select","select":{"name":"todo"}}
+	}}`), &obj); err != nil {
+		t.Fatalf("unmarshal object fail: %s", err)
+	}
+
+	if title := obj.GetTitle(); title != "Hello world" {
+		t.Errorf("unexpected title: %q", title)
+	}
+	if text := obj.GetText("Note"); text != "note" {
+		t.Errorf("unexpected text: %q", text)
+	}
+	if number, ok := obj.GetNumber("Count"); !ok || number != 3.5 {
+		t.Errorf("unexpected number: %v, %t", number, ok)
+	}
+	if _, ok := obj.GetNumber("Empty"); ok {
+		t.Errorf("empty number should not be ok")
+	}
+	if date, ok := obj.GetDate("Due"); !ok || date.Start != "2023-01-01" {
+		t.Errorf("unexpected date: %+v, %t", date, ok)
+	}
+	if _, ok := obj.GetDate("NoDue"); ok {
+		t.Errorf("empty date should not be ok")
+	}
+	if checked, ok := obj.GetCheckbox("Done"); !ok || checked {
+		t.Errorf("unexpected checkbox: %t, %t", checked, ok)
+	}
+	if sel := obj.GetSelect("Status"); sel != "todo" {
+		t.Errorf("unexpected select: %q", sel)
+	}
+	if _, ok := obj.GetCheckbox("Missing"); ok || obj.GetText("Missing") != "" || obj.GetSelect("Note") != "" {
+		t.Errorf("missing or mismatched property should return zero value")
+	}
+}
+
+func TestSearchManager_SearchStream(t *testing.T) {
+	pages := map[string]string{
+		"":        `{"object":"list","has_more":true,"next_cursor":"cursor1","results":[{"object":"page","id":"1"},{"object":"page","id":"2"}]}`,
+		"cursor1": `{"object":"list","has_more":false,"results":[{"object":"page","id":"3"}]}`,
+	}
+	baseURL := newMockServer(t, func(w http.ResponseWriter, r *http.Request) {
+		var payload struct {
+			Query       string         `json:"query"`
+			Filter      map[string]any `json:"filter"`
+			StartCursor string         `json:"start_cursor"`
+		}
+		if r.Method != http.MethodPost || r.URL.Path != "/v1/search" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil || payload.Query != "foo" || payload.Filter["value"] != "page" {
+			t.Errorf("unexpected payload: %+v, err: %v", payload, err)
+		}
+		_, _ = w.Write([]byte(pages[payload.StartCursor]))
+	})
+
+	ch, errCh := NewSearchManager(version, token).WithBaseURL(baseURL).SearchStream(context.Background(), "foo",
+		map[string]any{"property": "object", "value": "page"})
+
+	var ids []string
+	for obj := range ch {
+		ids = append(ids, obj.ID)
+	}
+	if err, ok := <-errCh; ok {
+		t.Errorf("search fail: %v", err)
+	}
+	if got := strings.Join(ids, ","); got != "1,2,3" {
+		t.Errorf("unexpected results: %s", got)
+	}
+}
+
+func TestCondition_QueryParams(t *testing.T) {
+	for _, testcase := range []struct {
+		cond     *Condition
+		expected string
+	}{
+		{nil, ""},
+		{&Condition{}, ""},
+		{&Condition{PageSize: 50}, "page_size=50"},
+		{&Condition{PageSize: 100, StartCursor: "a/b=c"}, "page_size=100&start_cursor=a%2Fb%3Dc"},
+		{&Condition{FilterProperties: []string{"title", "%3Aab"}, StartCursor: "next"}, "filter_properties=title&filter_properties=%253Aab&start_cursor=next"},
+	} {
+		if got := testcase.cond.QueryParams(); got != testcase.expected {
+			t.Errorf("%+v: unexpected query params: %q, expect: %q", testcase.cond, got, testcase.expected)
+		}
+	}
+}
+
+func TestManager_WithLimiter(t *testing.T) {
+	var mu sync.Mutex
+	var times []time.Time
+	baseURL := newMockServer(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		times = append(times, time.Now())
+		mu.Unlock()
+		_, _ = w.Write([]byte(`{"object":"list","has_more":false,"results":[]}`))
+	})
+
+	const limit = 50
+	limiter := rate.NewLimiter(limit, 1)
+	mgr := NewManager(version, token).WithBaseURL(baseURL).WithLimiter(limiter)
+	for name, l := range map[string]*rate.Limiter{
+		"database": mgr.Database("db-id").limiter,
+		"page":     mgr.Page("page-id").limiter,
+		"block":    mgr.Block("block-id").limiter,
+		"search":   mgr.SearchManager.limiter,
+	} {
+		if l != limiter {
+			t.Errorf("%s manager does not share limiter", name)
+		}
+	}
+
+	calls := []func(){
+		func() { _, _ = mgr.Database("db-id").Retrieve() },
+		func() { _, _ = mgr.Page("page-id").Retrieve() },
+		func() { _, _ = mgr.Block("block-id").GetAllChildren("block-id", 1) },
+		func() {
+			ch, _ := mgr.SearchStream(context.Background(), "", nil)
+			for range ch {
+			}
+		},
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(call func()) {
+			defer wg.Done()
+			call()
+		}(calls[i%len(calls)])
+	}
+	wg.Wait()
+
+	if len(times) != 10 {
+		t.Fatalf("expect 10 requests, got %d", len(times))
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+	// burst 1 allows first request immediately, then one request every 1/limit second
+	if elapsed := times[9].Sub(times[0]); elapsed < 9*time.Second/limit*9/10 {
+		t.Errorf("10 requests took %s, faster than limit %d/s", elapsed, limit)
+	}
+}
+
+func TestPropSortCondition(t *testing.T) {
+	data, err := json.Marshal(&Condition{Sorts: []PropSortCondition{
+		SortAscending("Name"),
+		SortDescending("Price"),
+		TimestampSort("last_edited_time", string(Descending)),
+	}})
+	if err != nil {
+		t.Fatalf("marshal sorts fail: %s", err)
+	}
+	expected := `{"sorts":[{"property":"Name","direction":"ascending"},{"property":"Price","direction":"descending"},` +
+		`{"timestamp":"last_edited_time","direction":"descending"}]}`
+	if string(data) != expected {
+		t.Errorf("unexpected sorts json: %s\n expect: %s", data, expected)
+	}
+}
+
+func TestRetryOn429(t *testing.T) {
+	var requests int32
+	baseURL := newMockServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(`{"object":"error","status":429,"code":"rate_limited","message":"rate limited"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"object":"page","id":"page-id"}`))
+	})
+
+	obj, err := NewManager(version, token).WithBaseURL(baseURL).Page("page-id").Retrieve()
+	if err != nil || obj.ID != "page-id" {
+		t.Errorf("expect page retrieved after retry, got: %+v, err: %v", obj, err)
+	}
+	if n := atomic.LoadInt32(&requests); n != 2 {
+		t.Errorf("expect retry exactly once, got %d requests", n)
+	}
+
+	// retry only once
+	atomic.StoreInt32(&requests, 0)
+	baseURL = newMockServer(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Header().Set("Retry-After", "0")
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+	if err := NewManager(version, token).WithBaseURL(baseURL).Page("").Create(PageItem{}); !errors.Is(err, ErrRateLimited) {
+		t.Errorf("expect ErrRateLimited, got: %v", err)
+	}
+	if n := atomic.LoadInt32(&requests); n != 2 {
+		t.Errorf("expect retry exactly once, got %d requests", n)
+	}
+}
+
+func Test_retryAfter(t *testing.T) {
+	for value, expected := range map[string]time.Duration{
+		"3":  3 * time.Second,
+		"0":  0,
+		"":   defaultRetryAfter,
+		"-1": defaultRetryAfter,
+		"x":  defaultRetryAfter,
+
+		time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat): 0,
+	} {
+		if got := retryAfter(http.Header{"Retry-After": {value}}); got != expected {
+			t.Errorf("%q: expect %s, got %s", value, expected, got)
+		}
+	}
+
+	date := time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)
+	if got := retryAfter(http.Header{"Retry-After": {date}}); got <= 59*time.Minute || got > time.Hour {
+		t.Errorf("expect about an hour for date %s, got %s", date, got)
+	}
+}
+
+func TestPageManager_CreateBatch(t *testing.T) {
+	var requests, inFlight, maxInFlight int32
+	baseURL := newMockServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/v1/pages" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		atomic.AddInt32(&requests, 1)
+		if n := atomic.AddInt32(&inFlight, 1); n > atomic.LoadInt32(&maxInFlight) {
+			atomic.StoreInt32(&maxInFlight, n)
+		}
+		defer atomic.AddInt32(&inFlight, -1)
+		time.Sleep(5 * time.Millisecond)
+
+		var payload struct {
+			Parent PageItem `json:"parent"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		if payload.Parent.DatabaseID == "db-3" || payload.Parent.DatabaseID == "db-7" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"object":"error","status":400,"code":"validation_error","message":"invalid"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"object":"page","id":"page-id"}`))
+	})
+
+	pages := make([]PageCreateRequest, 10)
+	for i := range pages {
+		pages[i] = PageCreateRequest{Parent: PageItem{DatabaseID: fmt.Sprintf("db-%d", i)}}
+	}
+	errs, err := NewPageManager(version, token).WithBaseURL(baseURL).WithLimiter(rate.NewLimiter(rate.Inf, 3)).CreateBatch(context.Background(), pages)
+	if err != nil {
+		t.Fatalf("create batch fail: %s", err)
+	}
+	for i, err := range errs {
+		if failed := i == 3 || i == 7; (err != nil) != failed {
+			t.Errorf("unexpected error of page %d: %v", i, err)
+		}
+	}
+	if n := atomic.LoadInt32(&requests); n != 10 {
+		t.Errorf("expect 10 requests, got %d", n)
+	}
+	if n := atomic.LoadInt32(&maxInFlight); n > 3 {
+		t.Errorf("expect at most burst 3 requests in flight, got %d", n)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	errs, err = NewPageManager(version, token).WithBaseURL(baseURL).CreateBatch(ctx, pages)
+	if !errors.Is(err, context.Canceled) || len(errs) != len(pages) {
+		t.Errorf("expect context canceled, got: %v", err)
+	}
+}
+
+func TestNewRollupFilter(t *testing.T) {
+	threshold := 10.0
+	rollup := NewRollupFilter().
+		Any(NumberProp, &NumberFilter{GreaterThan: &threshold}).
+		None(RichTextProp, &RichTextFilter{Contains: "draft"}).
+		Build()
+
+	data, err := json.Marshal(&FilterSingleCondition{Property: "Scores", Rollup: &rollup})
+	if err != nil {
+		t.Fatalf("marshal rollup filter fail: %s", err)
+	}
+	expected := `{"property":"Scores","rollup":{"any":{"number":{"greater_than":10}},"none":{"rich_text":{"contains":"draft"}}}}`
+	if string(data) != expected {
+		t.Errorf("unexpected rollup filter json: %s\n expect: %s", data, expected)
+	}
+
+	if every := NewRollupFilter().Every(DateProp, &DateFilter{IsEmpty: true}).Build(); every.Any != nil || every.Every == nil {
+		t.Errorf("unexpected rollup filter: %+v", every)
+	}
+}
+
+func TestManager_WithTimeout(t *testing.T) {
+	var delay atomic.Int64
+	baseURL := newMockServer(t, func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(time.Duration(delay.Load())):
+		case <-r.Context().Done():
+			return
+		}
+		_, _ = w.Write([]byte(`{"object":"page","id":"page-id"}`))
+	})
+
+	ctx := context.WithValue(context.Background(), struct{}{}, "value")
+	mgr := NewManager(version, token).WithBaseURL(baseURL).WithContext(ctx).WithTimeout(100 * time.Millisecond)
+	if mgr.Context() != ctx {
+		t.Errorf("expect manager context kept")
+	}
+
+	// timeout applies to each request separately
+	delay.Store(int64(60 * time.Millisecond))
+	for i := 0; i < 3; i++ {
+		if _, err := mgr.Page("page-id").Retrieve(); err != nil {
+			t.Fatalf("request %d should finish within timeout: %s", i, err)
+		}
+	}
+
+	delay.Store(int64(time.Second))
+	start := time.Now()
+	if _, err := mgr.Page("page-id").Retrieve(); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expect deadline exceeded, got: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("request should be cancelled after timeout, took %s", elapsed)
+	}
+}
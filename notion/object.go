@@ -2,6 +2,7 @@ package notion
 
 import (
 	"encoding/json"
+	"strconv"
 )
 
 // https://developers.notion.com/reference/request-limits
@@ -38,6 +39,64 @@ type Object struct {
 	Message string `json:"message,omitempty"`
 }
 
+// GetTitle return plain text of page title property, or title of database
+func (o *Object) GetTitle() string {
+	for _, prop := range o.Properties {
+		if prop.Type == TitleProp {
+			return prop.PlainText()
+		}
+	}
+
+	var title string
+	for _, t := range o.Title {
+		title += t.Content
+	}
+	return title
+}
+
+// GetText return plain text of property, empty if not found
+func (o *Object) GetText(name string) string { return o.Properties[name].PlainText() }
+
+// GetNumber return number property, false if not found or empty
+func (o *Object) GetNumber(name string) (float64, bool) {
+	prop, ok := o.Properties[name]
+	if !ok || prop.Type != NumberProp {
+		return 0, false
+	}
+	number, ok := prop.Number.(float64)
+	return number, ok
+}
+
+// GetDate return date property, false if not found or empty
+func (o *Object) GetDate(name string) (*DateObject, bool) {
+	prop, ok := o.Properties[name]
+	if !ok || prop.Type != DateProp || prop.Date == nil {
+		return nil, false
+	}
+	var date *DateObject
+	if err := json.Unmarshal(prop.Date, &date); err != nil || date == nil {
+		return nil, false
+	}
+	return date, true
+}
+
+// GetCheckbox return checkbox property, false if not found
+func (o *Object) GetCheckbox(name string) (checked bool, ok bool) {
+	prop, ok := o.Properties[name]
+	if !ok || prop.Type != CheckboxProp || prop.Checkbox == nil {
+		return false, false
+	}
+	return *prop.Checkbox, true
+}
+
+// GetSelect return option name of select property, empty if not found or not selected
+func (o *Object) GetSelect(name string) string {
+	if prop := o.Properties[name]; prop.Type == SelectProp {
+		return prop.PlainText()
+	}
+	return ""
+}
+
 // PureObject pure notion object
 type PureObject struct {
 	Object string `json:"object"`
@@ -175,3 +234,17 @@ func (o SelectOptionObject) JSON() json.RawMessage {
 	data, _ := json.Marshal(o)
 	return data
 }
+
+// UniqueIDObject unique id, auto incremented by notion
+type UniqueIDObject struct {
+	Number int     `json:"number"`
+	Prefix *string `json:"prefix"`
+}
+
+// String return unique id like PREFIX-1, or 1 if prefix not set
+func (o UniqueIDObject) String() string {
+	if o.Prefix == nil || *o.Prefix == "" {
+		return strconv.Itoa(o.Number)
+	}
+	return *o.Prefix + "-" + strconv.Itoa(o.Number)
+}
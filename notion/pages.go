@@ -5,8 +5,11 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"net/url"
 	"strconv"
+	"sync"
+	"time"
 
 	"golang.org/x/time/rate"
 
@@ -27,6 +30,8 @@ type PageManager struct {
 	*baseInfo
 
 	ctx     context.Context
+	timeout time.Duration
+	baseURL string // notion api url, default one if empty
 	id      string
 	limiter *rate.Limiter
 }
@@ -37,6 +42,18 @@ func (pm PageManager) WithContext(ctx context.Context) *PageManager {
 	return &pm
 }
 
+// WithTimeout set timeout for each request, zero means no timeout
+func (pm PageManager) WithTimeout(timeout time.Duration) *PageManager {
+	pm.timeout = timeout
+	return &pm
+}
+
+// WithBaseURL set notion api url, e.g. https://api.notion.com/v1, empty means default one
+func (pm PageManager) WithBaseURL(baseURL string) *PageManager {
+	pm.baseURL = baseURL
+	return &pm
+}
+
 // WithID set page id
 func (pm PageManager) WithID(id string) *PageManager {
 	pm.id = id
@@ -60,7 +77,9 @@ func (pm *PageManager) Retrieve() (*Object, error) {
 	log.CtxDebug(pm.ctx, "retrieve page %s", pm.id)
 
 	_ = pm.limiter.Wait(pm.ctx)
-	resp, err := fetch.CtxGet(pm.ctx, pm.api(retrieveOp), pm.Headers()...)
+	_, resp, err := retryOn429(pm.ctx, func() (int, []byte, http.Header, error) {
+		return fetch.DoRequestWithOptions(http.MethodGet, pm.api(retrieveOp), append(pm.Headers(), requestOptions(pm.ctx, pm.timeout)...), nil)
+	})
 	if err != nil {
 		return nil, fmt.Errorf("request api fail: %w", err)
 	}
@@ -76,33 +95,163 @@ func (pm *PageManager) Retrieve() (*Object, error) {
 // RetrieveProp retrieve page property item
 // docs: https://developers.notion.com/reference/retrieve-a-page-property
 func (pm *PageManager) RetrieveProp(propID string) (*Object, error) {
+	var obj, results = new(Object), make([]Object, 0, 60)
+	err := pm.retrieveProp(propID, func(resp []byte) (string, error) {
+		if err := json.Unmarshal(resp, obj); err != nil {
+			return "", fmt.Errorf("unmarshal response fail: %w", err)
+		}
+		results = append(results, obj.Results...)
+		if !obj.HasMore {
+			return "", nil
+		}
+		return obj.NextCursor, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	obj.Results = results
+	return obj, nil
+}
+
+// retrieveProp retrieve all pages of page property item, decode return next cursor
+func (pm *PageManager) retrieveProp(propID string, decode func(resp []byte) (nextCursor string, err error)) error {
 	log.CtxDebug(pm.ctx, "retrieve page %s property %s", pm.id, propID)
 
 	const pageSize = 30
 	param := url.Values{}
 	param.Set("page_size", strconv.Itoa(pageSize))
 
-	var obj, results = new(Object), make([]Object, 0, 2*pageSize)
-	for obj.HasMore = true; obj.HasMore; {
-		if obj.NextCursor != "" {
-			param.Set("start_cursor", obj.NextCursor)
+	for cursor, first := "", true; first || cursor != ""; first = false {
+		if cursor != "" {
+			param.Set("start_cursor", cursor)
 		}
 
 		_ = pm.limiter.Wait(pm.ctx)
-		resp, err := fetch.CtxGet(pm.ctx, pm.api(retrievePropOp)+propID+"?"+param.Encode(), pm.Headers()...)
+		_, resp, err := retryOn429(pm.ctx, func() (int, []byte, http.Header, error) {
+			return fetch.DoRequestWithOptions(http.MethodGet, pm.api(retrievePropOp)+propID+"?"+param.Encode(),
+				append(pm.Headers(), requestOptions(pm.ctx, pm.timeout)...), nil)
+		})
 		if err != nil {
-			return nil, fmt.Errorf("request api fail: %w", err)
+			return fmt.Errorf("request api fail: %w", err)
 		}
 		log.CtxDebug(pm.ctx, "retrieve page property got response %s", string(resp))
 
-		if err := json.Unmarshal(resp, obj); err != nil {
-			return nil, fmt.Errorf("unmarshal response fail: %w", err)
+		if cursor, err = decode(resp); err != nil {
+			return err
 		}
+	}
+	return nil
+}
 
-		results = append(results, obj.Results...)
+// retrievePropItem retrieve page property item, results of paginated property are merged
+func (pm *PageManager) retrievePropItem(propID string) (*propertyItem, error) {
+	var item propertyItem
+	var results []propertyItem
+	err := pm.retrieveProp(propID, func(resp []byte) (string, error) {
+		item = propertyItem{}
+		if err := json.Unmarshal(resp, &item); err != nil {
+			return "", fmt.Errorf("unmarshal response fail: %w", err)
+		}
+		if item.Object == "error" {
+			switch item.Status {
+			case 404:
+				return "", ErrNotFound
+			case 429:
+				return "", ErrRateLimited
+			default:
+				return "", fmt.Errorf("response error: [%d / %s] %s", item.Status, item.Code, item.Message)
+			}
+		}
+		results = append(results, item.Results...)
+		if !item.HasMore {
+			return "", nil
+		}
+		return item.NextCursor, nil
+	})
+	if err != nil {
+		return nil, err
 	}
-	obj.Results = results
-	return obj, nil
+
+	if item.Object == "list" { // paginated property, type is declared by property_item
+		item.Type = item.PropertyItem.Type
+		item.Results = results
+	}
+	return &item, nil
+}
+
+// GetText get text of title or rich_text property
+func (pm *PageManager) GetText(propID string) (string, error) {
+	item, err := pm.retrievePropItem(propID)
+	if err != nil {
+		return "", err
+	}
+	if item.Type != TitleProp && item.Type != RichTextProp {
+		return "", fmt.Errorf("property %s is %s, not text", propID, item.Type)
+	}
+
+	var text string
+	for _, result := range append([]propertyItem{*item}, item.Results...) {
+		for _, t := range []*TextObject{result.Title, result.RichText} {
+			if t != nil {
+				text += t.PlainText
+			}
+		}
+	}
+	return text, nil
+}
+
+// GetNumber get number property, return 0 if empty
+func (pm *PageManager) GetNumber(propID string) (float64, error) {
+	item, err := pm.retrievePropItem(propID)
+	if err != nil {
+		return 0, err
+	}
+	if item.Type != NumberProp {
+		return 0, fmt.Errorf("property %s is %s, not number", propID, item.Type)
+	}
+	if item.Number == nil {
+		return 0, nil
+	}
+	return *item.Number, nil
+}
+
+// GetDate get date property, return nil if empty
+func (pm *PageManager) GetDate(propID string) (*DateObject, error) {
+	item, err := pm.retrievePropItem(propID)
+	if err != nil {
+		return nil, err
+	}
+	if item.Type != DateProp {
+		return nil, fmt.Errorf("property %s is %s, not date", propID, item.Type)
+	}
+	return item.Date, nil
+}
+
+// GetCheckbox get checkbox property
+func (pm *PageManager) GetCheckbox(propID string) (bool, error) {
+	item, err := pm.retrievePropItem(propID)
+	if err != nil {
+		return false, err
+	}
+	if item.Type != CheckboxProp {
+		return false, fmt.Errorf("property %s is %s, not checkbox", propID, item.Type)
+	}
+	return item.Checkbox != nil && *item.Checkbox, nil
+}
+
+// GetSelect get option name of select property, return empty string if not selected
+func (pm *PageManager) GetSelect(propID string) (string, error) {
+	item, err := pm.retrievePropItem(propID)
+	if err != nil {
+		return "", err
+	}
+	if item.Type != SelectProp {
+		return "", fmt.Errorf("property %s is %s, not select", propID, item.Type)
+	}
+	if item.Select == nil {
+		return "", nil
+	}
+	return item.Select.Name, nil
 }
 
 // Create create page
@@ -119,8 +268,10 @@ func (pm *PageManager) Create(parent PageItem, properties ...*Property) error {
 		Properties: PropertyArray(properties).ForUpdate(),
 	})
 	_ = pm.limiter.Wait(pm.ctx)
-	statusCode, resp, _, err := fetch.DoRequestWithOptions("POST", pm.api(createOp),
-		append(pm.Headers(), fetch.WithContext(pm.ctx)), bytes.NewReader(payload))
+	statusCode, resp, err := retryOn429(pm.ctx, func() (int, []byte, http.Header, error) {
+		return fetch.DoRequestWithOptions(http.MethodPost, pm.api(createOp),
+			append(pm.Headers(), requestOptions(pm.ctx, pm.timeout)...), bytes.NewReader(payload))
+	})
 	if err != nil {
 		return fmt.Errorf("request api fail: %w", err)
 	}
@@ -134,6 +285,45 @@ func (pm *PageManager) Create(parent PageItem, properties ...*Property) error {
 	return nil
 }
 
+// PageCreateRequest parent and properties of page to create
+type PageCreateRequest struct {
+	Parent     PageItem
+	Properties []*Property
+}
+
+// CreateBatch create pages concurrently up to burst of limiter, errs[i] is error of pages[i],
+// err is ctx.Err() if ctx done before all pages created, pages not created then get same error
+func (pm *PageManager) CreateBatch(ctx context.Context, pages []PageCreateRequest) (errs []error, err error) {
+	concurrency := 1
+	if pm.limiter != nil && pm.limiter.Burst() > concurrency {
+		concurrency = pm.limiter.Burst()
+	}
+	sem := make(chan struct{}, concurrency)
+
+	page := pm.WithContext(ctx)
+	errs = make([]error, len(pages))
+
+	var wg sync.WaitGroup
+	for i, req := range pages {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			errs[i] = ctx.Err()
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, req PageCreateRequest) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			errs[i] = page.Create(req.Parent, req.Properties...)
+		}(i, req)
+	}
+	wg.Wait()
+
+	return errs, ctx.Err()
+}
+
 // Update update page
 // docs: https://developers.notion.com/reference/patch-page
 // PATCH https://api.notion.com/v1/pages/{page_id}
@@ -144,7 +334,10 @@ func (pm *PageManager) Update(properties ...*Property) error {
 	log.CtxDebug(pm.ctx, "update page with payload: %s", string(payload))
 
 	_ = pm.limiter.Wait(pm.ctx)
-	resp, err := fetch.CtxPatch(pm.ctx, pm.api(updateOp), bytes.NewReader(payload), pm.Headers()...)
+	_, resp, err := retryOn429(pm.ctx, func() (int, []byte, http.Header, error) {
+		return fetch.DoRequestWithOptions(http.MethodPatch, pm.api(updateOp),
+			append(pm.Headers(), requestOptions(pm.ctx, pm.timeout)...), bytes.NewReader(payload))
+	})
 	if err != nil {
 		return fmt.Errorf("request api fail: %w", err)
 	}
@@ -173,7 +366,10 @@ func (pm *PageManager) Trash() error {
 	payload, _ := json.Marshal(map[string]any{"in_trash": true})
 
 	_ = pm.limiter.Wait(pm.ctx)
-	resp, err := fetch.CtxPatch(pm.ctx, pm.api(updateOp), bytes.NewReader(payload), pm.Headers()...)
+	_, resp, err := retryOn429(pm.ctx, func() (int, []byte, http.Header, error) {
+		return fetch.DoRequestWithOptions(http.MethodPatch, pm.api(updateOp),
+			append(pm.Headers(), requestOptions(pm.ctx, pm.timeout)...), bytes.NewReader(payload))
+	})
 	if err != nil {
 		return fmt.Errorf("request api fail: %w", err)
 	}
@@ -195,7 +391,7 @@ func (pm *PageManager) Trash() error {
 
 // api return page api
 func (pm *PageManager) api(typ operateType) string {
-	baseAPI := notionAPI() + "/pages"
+	baseAPI := notionAPI(pm.baseURL) + "/pages"
 	switch typ {
 	case createOp: // POST https://api.notion.com/v1/pages
 		return baseAPI
@@ -2,6 +2,7 @@ package notion
 
 import (
 	"encoding/json"
+	"strconv"
 )
 
 // PropertyType property type
@@ -18,7 +19,9 @@ const (
 	DateProp        PropertyType = "date"
 	CheckboxProp    PropertyType = "checkbox"
 	RelationProp    PropertyType = "relation"
-	RollupProp      PropertyType = "rollup" // do not used when update
+	RollupProp      PropertyType = "rollup"    // do not used when update
+	UniqueIDProp    PropertyType = "unique_id" // read only
+	EmailProp       PropertyType = "email"
 )
 
 // Property
@@ -39,6 +42,8 @@ type Property struct {
 	Checkbox    *bool           `json:"checkbox,omitempty"`
 	Relation    json.RawMessage `json:"relation,omitempty"`
 	Rollup      json.RawMessage `json:"rollup,omitempty"`
+	UniqueID    *UniqueIDObject `json:"unique_id,omitempty"`
+	Email       json.RawMessage `json:"email,omitempty"`
 }
 
 // ForUpdate return update format data
@@ -64,6 +69,8 @@ func (p Property) ForUpdate() (data json.RawMessage) {
 		data, _ = json.Marshal(map[PropertyType]bool{CheckboxProp: *p.Checkbox})
 	case p.Relation != nil:
 		data, _ = json.Marshal(map[PropertyType]json.RawMessage{RelationProp: p.Relation})
+	case p.Email != nil:
+		data, _ = json.Marshal(map[PropertyType]json.RawMessage{EmailProp: p.Email})
 	}
 	return data
 }
@@ -128,11 +135,84 @@ func (p Property) PlainText() (text string) {
 			return ""
 		}
 		return url
+	case EmailProp:
+		if p.Email == nil {
+			return ""
+		}
+		var email string
+		if err := json.Unmarshal(p.Email, &email); err != nil {
+			return ""
+		}
+		return email
+	case UniqueIDProp:
+		if p.UniqueID == nil {
+			return ""
+		}
+		return p.UniqueID.String()
 	default:
 		return ""
 	}
 }
 
+// PlainValue return property value in plain string, empty if value not set or type unsupported
+func (p Property) PlainValue() string {
+	switch p.Type {
+	case NumberProp:
+		switch v := p.Number.(type) {
+		case nil:
+			return ""
+		case float64:
+			return strconv.FormatFloat(v, 'f', -1, 64)
+		default:
+			data, _ := json.Marshal(v)
+			return string(data)
+		}
+	case DateProp:
+		var date DateObject
+		if p.Date == nil || json.Unmarshal(p.Date, &date) != nil {
+			return ""
+		}
+		if date.End != "" {
+			return date.Start + "/" + date.End
+		}
+		return date.Start
+	case CheckboxProp:
+		if p.Checkbox == nil {
+			return ""
+		}
+		return strconv.FormatBool(*p.Checkbox)
+	default:
+		return p.PlainText()
+	}
+}
+
+// propertyItem page property item returned by retrieve page property api
+// https://developers.notion.com/reference/property-item-object
+type propertyItem struct {
+	PureObject
+	Type PropertyType `json:"type"`
+
+	Title    *TextObject         `json:"title,omitempty"`
+	RichText *TextObject         `json:"rich_text,omitempty"`
+	Number   *float64            `json:"number,omitempty"`
+	Date     *DateObject         `json:"date,omitempty"`
+	Checkbox *bool               `json:"checkbox,omitempty"`
+	Select   *SelectOptionObject `json:"select,omitempty"`
+
+	// paginated property
+	Results      []propertyItem `json:"results,omitempty"`
+	NextCursor   string         `json:"next_cursor,omitempty"`
+	HasMore      bool           `json:"has_more,omitempty"`
+	PropertyItem struct {
+		ID   string       `json:"id"`
+		Type PropertyType `json:"type"`
+	} `json:"property_item,omitempty"`
+
+	Status  int    `json:"status,omitempty"`
+	Code    string `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
 type PropertyArray []*Property
 
 func (pa PropertyArray) ForUpdate() json.RawMessage {
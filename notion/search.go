@@ -1,9 +1,17 @@
 package notion
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
 
 	"golang.org/x/time/rate"
+
+	"github.com/tr1v3r/pkg/fetch"
+	"github.com/tr1v3r/pkg/log"
 )
 
 // NewSearchManager return a new search manager
@@ -11,7 +19,7 @@ func NewSearchManager(version, token string) *SearchManager {
 	return &SearchManager{baseInfo: &baseInfo{
 		NotionVersion: version,
 		BearerToken:   token,
-	}, ctx: context.Background()}
+	}, ctx: context.Background(), limiter: rate.NewLimiter(rateLimit, 4*rateLimit)}
 }
 
 // SearchManager ...
@@ -19,6 +27,8 @@ type SearchManager struct {
 	*baseInfo
 
 	ctx     context.Context
+	timeout time.Duration
+	baseURL string // notion api url, default one if empty
 	limiter *rate.Limiter
 }
 
@@ -28,8 +38,96 @@ func (sm SearchManager) WithContext(ctx context.Context) *SearchManager {
 	return &sm
 }
 
+// WithTimeout set timeout for each request, zero means no timeout
+func (sm SearchManager) WithTimeout(timeout time.Duration) *SearchManager {
+	sm.timeout = timeout
+	return &sm
+}
+
+// WithBaseURL set notion api url, e.g. https://api.notion.com/v1, empty means default one
+func (sm SearchManager) WithBaseURL(baseURL string) *SearchManager {
+	sm.baseURL = baseURL
+	return &sm
+}
+
 // WithLimiter with limiiter
 func (sm SearchManager) WithLimiter(limiter *rate.Limiter) *SearchManager {
 	sm.limiter = limiter
 	return &sm
 }
+
+// SearchStream search pages and databases shared with integration, results are fetched page by page lazily
+// both channels are closed after last page is sent, search fails or ctx is done
+// docs: https://developers.notion.com/reference/post-search
+// POST https://api.notion.com/v1/search
+func (sm *SearchManager) SearchStream(ctx context.Context, query string, filter map[string]any) (<-chan Object, <-chan error) {
+	const pageSize = 100
+
+	log.CtxDebug(ctx, "search %q", query)
+
+	ch := make(chan Object, pageSize)
+	errCh := make(chan error, 1)
+
+	go func() {
+		defer close(errCh)
+		defer close(ch)
+
+		var count int
+		var obj = new(Object)
+		for obj.HasMore = true; obj.HasMore; {
+			payload := map[string]any{"page_size": pageSize}
+			if query != "" {
+				payload["query"] = query
+			}
+			if filter != nil {
+				payload["filter"] = filter
+			}
+			if obj.NextCursor != "" {
+				payload["start_cursor"] = obj.NextCursor
+			}
+			data, _ := json.Marshal(payload)
+
+			if sm.limiter != nil {
+				if err := sm.limiter.Wait(ctx); err != nil {
+					errCh <- err
+					return
+				}
+			}
+			_, resp, err := retryOn429(ctx, func() (int, []byte, http.Header, error) {
+				return fetch.DoRequestWithOptions(http.MethodPost, notionAPI(sm.baseURL)+"/search",
+					append(sm.Headers(), requestOptions(ctx, sm.timeout)...), bytes.NewReader(data))
+			})
+			if err != nil {
+				errCh <- fmt.Errorf("search %q fail: %w", query, err)
+				return
+			}
+
+			obj = new(Object)
+			if err := json.Unmarshal(resp, obj); err != nil {
+				errCh <- fmt.Errorf("unmarshal search result fail: %w", err)
+				return
+			}
+			if obj.Object == "error" {
+				if obj.Status == 429 {
+					errCh <- ErrRateLimited
+				} else {
+					errCh <- fmt.Errorf("search fail: [%d / %s] %s", obj.Status, obj.Code, obj.Message)
+				}
+				return
+			}
+
+			for _, result := range obj.Results {
+				select {
+				case ch <- result:
+				case <-ctx.Done():
+					errCh <- ctx.Err()
+					return
+				}
+			}
+			count += len(obj.Results)
+			log.CtxDebug(ctx, "total searched %d items, next cursor: %s", count, obj.NextCursor)
+		}
+	}()
+
+	return ch, errCh
+}
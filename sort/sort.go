@@ -1,9 +1,26 @@
 package sort
 
 import (
+	"fmt"
+	"math/bits"
 	"sort"
 )
 
+// Sorter sort slice by certain criterion
+type Sorter[T any] interface {
+	// Sort sort items in place
+	Sort(items []*T)
+	// IsSorted report whether items is sorted
+	IsSorted(items []*T) bool
+	// CopySort return a sorted copy of items, items is left untouched
+	CopySort(items []*T) []*T
+}
+
+var (
+	_ Sorter[struct{}] = By[struct{}](nil)
+	_ Sorter[struct{}] = (*multiSorter[struct{}])(nil)
+)
+
 // ============ single sort ============
 
 // By is the type of a "less" function that defines the ordering of its T arguments.
@@ -17,6 +34,16 @@ func (by By[T]) Sort(items []*T) {
 	})
 }
 
+// IsSorted report whether items is sorted according to the function.
+func (by By[T]) IsSorted(items []*T) bool { return sort.IsSorted(&sorter[T]{items: items, by: by}) }
+
+// CopySort return a copy of items sorted according to the function.
+func (by By[T]) CopySort(items []*T) []*T {
+	sorted := append([]*T(nil), items...)
+	by.Sort(sorted)
+	return sorted
+}
+
 // sorter joins a By function and a slice of T to be sorted.
 type sorter[T any] struct {
 	items []*T
@@ -34,7 +61,7 @@ func ReverseBy[T any](by By[T]) By[T] { return func(l, r *T) bool { return by(r,
 
 // MultiBy returns a Sorter that sorts using the less functions, in order.
 // Call its Sort method to sort the data.
-func MultiBy[T any](less ...By[T]) *multiSorter[T] { return &multiSorter[T]{less: less} }
+func MultiBy[T any](less ...By[T]) Sorter[T] { return &multiSorter[T]{less: less} }
 
 type multiSorter[T any] struct {
 	items []*T
@@ -42,9 +69,21 @@ type multiSorter[T any] struct {
 }
 
 // Sort sorts the argument slice according to the less functions passed to OrderedBy.
-func (ms *multiSorter[T]) Sort(items []*T) {
-	ms.items = items
-	sort.Sort(ms)
+func (ms *multiSorter[T]) Sort(items []*T) { sort.Sort(ms.with(items)) }
+
+// IsSorted report whether items is sorted according to the less functions.
+func (ms *multiSorter[T]) IsSorted(items []*T) bool { return sort.IsSorted(ms.with(items)) }
+
+// CopySort return a copy of items sorted according to the less functions.
+func (ms *multiSorter[T]) CopySort(items []*T) []*T {
+	sorted := append([]*T(nil), items...)
+	ms.Sort(sorted)
+	return sorted
+}
+
+// with return a multiSorter holding items, so ms itself can be shared between goroutines
+func (ms *multiSorter[T]) with(items []*T) *multiSorter[T] {
+	return &multiSorter[T]{items: items, less: ms.less}
 }
 
 // implement of sort.Interface
@@ -75,3 +114,122 @@ func (ms *multiSorter[T]) Less(i, j int) bool {
 	// the final comparison reports.
 	return ms.less[k](l, r)
 }
+
+// ============ map sort ============
+
+// SortMapKeys return keys of m in ascending order of their values according to less
+// keys with equal values are ordered by their string form, so result is stable across calls
+func SortMapKeys[K comparable, V any](m map[K]V, less func(a, b V) bool) []K {
+	keys := make([]K, 0, len(m))
+	names := make(map[K]string, len(m))
+	for k := range m {
+		keys = append(keys, k)
+		names[k] = fmt.Sprint(k)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		l, r := m[keys[i]], m[keys[j]]
+		switch {
+		case less(l, r):
+			return true
+		case less(r, l):
+			return false
+		default:
+			return names[keys[i]] < names[keys[j]]
+		}
+	})
+	return keys
+}
+
+// SortMapEntries return key-value pairs of m in ascending order of values according to less
+func SortMapEntries[K comparable, V any](m map[K]V, less func(a, b V) bool) []struct {
+	Key   K
+	Value V
+} {
+	keys := SortMapKeys(m, less)
+	entries := make([]struct {
+		Key   K
+		Value V
+	}, len(keys))
+	for i, k := range keys {
+		entries[i].Key, entries[i].Value = k, m[k]
+	}
+	return entries
+}
+
+// ============ selection ============
+
+// PartialSort rearrange slice so that the k-th smallest element is at index k, elements before it are not greater
+// and elements after it are not less than it, in O(n) average time. k out of range is ignored
+func PartialSort[T any](slice []T, k int, less func(*T, *T) bool) {
+	if k < 0 || k >= len(slice) {
+		return
+	}
+
+	lo, hi := 0, len(slice)-1
+	// introselect: fall back to sorting when partitioning goes bad too many times
+	for depth := 2 * bits.Len(uint(len(slice))); lo < hi; depth-- {
+		if depth == 0 {
+			sub := slice[lo : hi+1]
+			sort.Slice(sub, func(i, j int) bool { return less(&sub[i], &sub[j]) })
+			return
+		}
+
+		p := partition(slice, lo, hi, less)
+		switch {
+		case k < p:
+			hi = p - 1
+		case k > p:
+			lo = p + 1
+		default:
+			return
+		}
+	}
+}
+
+// partition partition slice[lo:hi+1] around median of three, return final index of pivot
+func partition[T any](slice []T, lo, hi int, less func(*T, *T) bool) int {
+	mid := lo + (hi-lo)/2
+	if less(&slice[mid], &slice[lo]) {
+		slice[mid], slice[lo] = slice[lo], slice[mid]
+	}
+	if less(&slice[hi], &slice[lo]) {
+		slice[hi], slice[lo] = slice[lo], slice[hi]
+	}
+	if less(&slice[hi], &slice[mid]) {
+		slice[hi], slice[mid] = slice[mid], slice[hi]
+	}
+	slice[mid], slice[hi] = slice[hi], slice[mid] // median as pivot at hi
+
+	i := lo
+	for j := lo; j < hi; j++ {
+		if less(&slice[j], &slice[hi]) {
+			slice[i], slice[j] = slice[j], slice[i]
+			i++
+		}
+	}
+	slice[i], slice[hi] = slice[hi], slice[i]
+	return i
+}
+
+// ============ deduplicate ============
+
+// UniqueBy return a new slice with consecutive duplicates in sorted removed, the first one of duplicates is kept
+func UniqueBy[T any](sorted []T, equal func(*T, *T) bool) []T {
+	if sorted == nil {
+		return nil
+	}
+
+	unique := make([]T, 0, len(sorted))
+	for i := range sorted {
+		if i == 0 || !equal(&unique[len(unique)-1], &sorted[i]) {
+			unique = append(unique, sorted[i])
+		}
+	}
+	return unique
+}
+
+// UniqueByLess same as UniqueBy, elements neither less than the other are duplicates
+func UniqueByLess[T any](sorted []T, less func(*T, *T) bool) []T {
+	return UniqueBy(sorted, func(a, b *T) bool { return !less(a, b) && !less(b, a) })
+}
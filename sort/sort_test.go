@@ -2,7 +2,9 @@ package sort_test
 
 import (
 	"fmt"
+	"math/rand"
 	"reflect"
+	stdsort "sort"
 	"testing"
 
 	"github.com/tr1v3r/pkg/sort"
@@ -130,6 +132,40 @@ func Test_SortMultiKeys(t *testing.T) {
 
 }
 
+func Test_Sorter(t *testing.T) {
+	mass := func(p1, p2 *Planet) bool { return p1.mass < p2.mass }
+	name := func(p1, p2 *Planet) bool { return p1.name < p2.name }
+
+	var byMass sort.Sorter[Planet] = sort.By[Planet](mass)
+	var byMassName sort.Sorter[Planet] = sort.MultiBy[Planet](mass, name)
+
+	for _, sorter := range []sort.Sorter[Planet]{byMass, byMassName} {
+		items := append([]*Planet(nil), planets...)
+		sort.By[Planet](name).Sort(items)
+		origin := toIDSlice(items)
+
+		if sorter.IsSorted(items) {
+			t.Errorf("expect %v not sorted by mass", origin)
+		}
+
+		sorted := sorter.CopySort(items)
+		if order := []int{1, 4, 2, 3}; !matchIDSort(sorted, order...) {
+			t.Errorf("mismatch copy sort result, expected: %+v, got: %+v", order, toIDSlice(sorted))
+		}
+		if !matchIDSort(items, origin...) {
+			t.Errorf("expect items untouched by copy sort, got: %+v", toIDSlice(items))
+		}
+		if !sorter.IsSorted(sorted) {
+			t.Errorf("expect %v sorted by mass", toIDSlice(sorted))
+		}
+
+		sorter.Sort(items)
+		if !matchIDSort(items, 1, 4, 2, 3) || !sorter.IsSorted(items) {
+			t.Errorf("mismatch sort result, got: %+v", toIDSlice(items))
+		}
+	}
+}
+
 type Item interface{ ID() int }
 
 func matchIDSort(tgt any, order ...int) bool {
@@ -163,3 +199,109 @@ func toIDSlice(tgt any) (ret []int) {
 	}
 	return ret
 }
+
+func Test_SortMapKeys(t *testing.T) {
+	scores := map[string]int{"alice": 3, "bob": 1, "carol": 2}
+	if keys := sort.SortMapKeys(scores, func(a, b int) bool { return a < b }); !reflect.DeepEqual(keys, []string{"bob", "carol", "alice"}) {
+		t.Errorf("mismatch sort result by int value: %v", keys)
+	}
+
+	names := map[int]string{1: "pear", 2: "apple", 3: "fig"}
+	if keys := sort.SortMapKeys(names, func(a, b string) bool { return a < b }); !reflect.DeepEqual(keys, []int{2, 3, 1}) {
+		t.Errorf("mismatch sort result by string value: %v", keys)
+	}
+
+	// keys with duplicate values must be in same order every time
+	dup := map[string]int{"e": 1, "d": 0, "c": 1, "b": 0, "a": 1}
+	for i := 0; i < 10; i++ {
+		if keys := sort.SortMapKeys(dup, func(a, b int) bool { return a < b }); !reflect.DeepEqual(keys, []string{"b", "d", "a", "c", "e"}) {
+			t.Errorf("mismatch sort result with duplicate values: %v", keys)
+		}
+	}
+}
+
+func Test_SortMapEntries(t *testing.T) {
+	entries := sort.SortMapEntries(map[string]int{"alice": 3, "bob": 1, "carol": 2}, func(a, b int) bool { return a > b })
+	if got := fmt.Sprint(entries); got != "[{alice 3} {carol 2} {bob 1}]" {
+		t.Errorf("mismatch sort entries: %s", got)
+	}
+}
+
+func Test_PartialSort(t *testing.T) {
+	less := func(a, b *int) bool { return *a < *b }
+	r := rand.New(rand.NewSource(1))
+
+	for _, size := range []int{1, 2, 3, 10, 101, 1000} {
+		for _, k := range []int{0, size / 4, size / 2, size - 1} {
+			for _, maxValue := range []int{5, 1 << 20} { // with many or few duplicates
+				items := make([]int, size)
+				for i := range items {
+					items[i] = r.Intn(maxValue)
+				}
+				expected := append([]int(nil), items...)
+				stdsort.Ints(expected)
+
+				sort.PartialSort(items, k, less)
+				if items[k] != expected[k] {
+					t.Errorf("size %d: expect %d-th element %d, got %d", size, k, expected[k], items[k])
+				}
+				for i := range items {
+					if i < k && items[i] > items[k] || i > k && items[i] < items[k] {
+						t.Errorf("size %d, k %d: element %d at %d not partitioned", size, k, items[i], i)
+						break
+					}
+				}
+			}
+		}
+	}
+
+	// k out of range is ignored
+	items := []int{3, 1, 2}
+	sort.PartialSort(items, 3, less)
+	if !reflect.DeepEqual(items, []int{3, 1, 2}) {
+		t.Errorf("slice should not be modified with k out of range: %v", items)
+	}
+}
+
+func Test_UniqueBy(t *testing.T) {
+	mass := func(p1, p2 *Planet) bool { return p1.mass < p2.mass }
+	items := []Planet{{1, "Mercury", 0.055, 0.4}, {2, "Venus", 0.815, 0.7}, {3, "Earth", 1.0, 1.0}, {4, "Mars", 0.107, 1.5},
+		{5, "Mercury II", 0.055, 0.5}, {6, "Earth II", 1.0, 1.1}}
+	stdsort.SliceStable(items, func(i, j int) bool { return mass(&items[i], &items[j]) })
+
+	unique := sort.UniqueByLess(items, mass)
+	var names []string
+	for _, p := range unique {
+		names = append(names, p.name)
+	}
+	if got := fmt.Sprint(names); got != "[Mercury Mars Venus Earth]" {
+		t.Errorf("mismatch unique planets by mass: %s", got)
+	}
+	if len(items) != 6 {
+		t.Errorf("input slice should not be modified")
+	}
+
+	user := func(c1, c2 *Change) bool { return c1.user < c2.user }
+	language := func(c1, c2 *Change) bool { return c1.language < c2.language }
+	sorted := make([]Change, 0, len(changes))
+	for _, c := range changes {
+		sorted = append(sorted, *c)
+	}
+	stdsort.SliceStable(sorted, func(i, j int) bool {
+		return user(&sorted[i], &sorted[j]) || !user(&sorted[j], &sorted[i]) && language(&sorted[i], &sorted[j])
+	})
+	sorted = append(sorted, sorted[len(sorted)-1]) // duplicate of last change
+
+	uniqueChanges := sort.UniqueBy(sorted, func(a, b *Change) bool { return a.user == b.user && a.language == b.language })
+	var keys []string
+	for _, c := range uniqueChanges {
+		keys = append(keys, c.user+"/"+c.language)
+	}
+	if got := fmt.Sprint(keys); got != "[dmr/C glenda/Go gri/Go gri/Smalltalk ken/C ken/Go r/C r/Go rsc/Go]" {
+		t.Errorf("mismatch unique changes by user and language: %s", got)
+	}
+
+	if sort.UniqueBy[int](nil, nil) != nil || len(sort.UniqueByLess([]int{}, func(a, b *int) bool { return *a < *b })) != 0 {
+		t.Errorf("expect empty result of empty input")
+	}
+}
@@ -1,6 +1,8 @@
 package thread
 
 import (
+	"context"
+	"errors"
 	"sync"
 	"time"
 )
@@ -10,12 +12,30 @@ const (
 	defaultJobQueueLength    = 10000 // 默认任务队列长度
 )
 
+var (
+	// ErrDeadlineExceeded 任务在截止时间前未能提交
+	ErrDeadlineExceeded = errors.New("submit deadline exceeded")
+	// ErrPoolClosed 协程池已关闭，不再接受新任务
+	ErrPoolClosed = errors.New("pool closed")
+	// ErrRateLimited 超过任务提交速率
+	ErrRateLimited = errors.New("submit rate exceeded")
+	// ErrQueueFull 任务队列已满
+	ErrQueueFull = errors.New("job queue full")
+)
+
 // Job ...
 type Job struct {
 	Handler func(v ...interface{})
 	Params  []interface{}
 }
 
+// ContextJob 携带 context 的任务，context 作为第一个参数传入 Handler，任务需自行响应 ctx.Done()
+type ContextJob struct {
+	Ctx     context.Context
+	Handler func(ctx context.Context, v ...interface{})
+	Params  []interface{}
+}
+
 // TimeoutPool ...
 type TimeoutPool struct {
 	workerQueue chan *worker
@@ -25,6 +45,18 @@ type TimeoutPool struct {
 	stop        chan struct{}
 	terminated  chan struct{}
 	lock        sync.Mutex
+
+	startOnce sync.Once
+	closed    bool           // 关闭后不再接受新任务
+	pending   sync.WaitGroup // 已提交但未执行完毕的任务
+
+	stopOnce  sync.Once
+	stopped   bool // 已调用 Terminate
+	drainOnce sync.Once
+	drained   chan struct{} // DrainAndClose 等待的任务全部执行完毕且协程池停止后关闭
+
+	unfinished int           // 已提交但未执行完毕的任务数量
+	idle       chan struct{} // 未执行完毕的任务全部完成时关闭
 }
 
 // NewTimeoutPoolWithDefaults 初始化一个带有执行超时时间的协程池，协程数量：10；任务队列长度1000
@@ -39,22 +71,123 @@ func NewTimeoutPool(workerQueueLen, jobQueueLen int) *TimeoutPool {
 		jobQueue:    make(chan *Job, jobQueueLen),
 		jobRet:      make(chan struct{}, jobQueueLen),
 		stop:        make(chan struct{}),
-		terminated:  make(chan struct{}),
+		terminated:  make(chan struct{}, 1),
 	}
 }
 
-// Terminate 停止协程池运行，如果有正在运行中的任务会等待其运行完毕
+// Terminate 停止协程池运行，如果有正在运行中的任务会等待其运行完毕，重复调用直接返回
 func (p *TimeoutPool) Terminate() {
-	p.stop <- struct{}{}
+	p.stopOnce.Do(func() {
+		p.lock.Lock()
+		p.stopped = true
+		p.lock.Unlock()
+
+		p.stop <- struct{}{}
+	})
 }
 
-// Submit 提交一个任务到协程池
+// Submit 提交一个任务到协程池，协程池关闭后提交的任务会被丢弃
 func (p *TimeoutPool) Submit(job *Job) {
+	if !p.accept() {
+		return
+	}
 	p.jobQueue <- job
+}
 
-	p.lock.Lock()
-	p.jobCount++
-	p.lock.Unlock()
+// SubmitWithDeadline 在截止时间前提交任务到协程池，任务队列已满且超过截止时间时丢弃任务并返回 ErrDeadlineExceeded
+func (p *TimeoutPool) SubmitWithDeadline(job *Job, deadline time.Time) error {
+	wait := time.Until(deadline)
+	if wait <= 0 {
+		return ErrDeadlineExceeded
+	}
+	if !p.accept() {
+		return ErrPoolClosed
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case p.jobQueue <- job:
+		return nil
+	case <-timer.C:
+		p.reject()
+		return ErrDeadlineExceeded
+	}
+}
+
+// SubmitContextJob 提交一个携带 context 的任务到协程池，ctx 在提交前结束时返回 ctx.Err()
+// 协程池不会取消已提交的任务
+func (p *TimeoutPool) SubmitContextJob(job *ContextJob) error {
+	ctx := job.Ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+	if !p.accept() {
+		return ErrPoolClosed
+	}
+
+	select {
+	case p.jobQueue <- &Job{Handler: func(v ...interface{}) { job.Handler(ctx, v...) }, Params: job.Params}:
+		return nil
+	case <-ctx.Done():
+		p.reject()
+		return ctx.Err()
+	}
+}
+
+// DrainAndClose 关闭协程池：不再接受新任务，等待已提交的任务全部执行完毕后停止协程池
+// ctx 在任务执行完毕前结束时返回 ctx.Err()，剩余任务仍会继续执行，执行完毕后协程池自动停止
+// 协程池未启动时由 DrainAndClose 启动；协程池已停止时直接返回，重复调用等待同一次关闭
+func (p *TimeoutPool) DrainAndClose(ctx context.Context) error {
+	p.drainOnce.Do(func() {
+		p.lock.Lock()
+		p.closed = true
+		stopped := p.stopped
+		p.lock.Unlock()
+
+		p.drained = make(chan struct{})
+		if stopped { // 已停止的协程池不会再执行任务，无需等待
+			close(p.drained)
+			return
+		}
+
+		var owner bool // 由本方法启动时，需要自行消费任务完成通知
+		p.startOnce.Do(func() {
+			owner = true
+			p.start()
+		})
+
+		go func() {
+			p.pending.Wait()
+			p.Terminate()
+			close(p.drained)
+		}()
+		if owner {
+			go func() {
+				for {
+					select {
+					case <-p.jobRet:
+					case <-p.drained:
+						return
+					}
+				}
+			}()
+		}
+	})
+
+	select {
+	case <-p.drained:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
 
 // StartAndWaitUntilTerminated 启动并等待协程池内的运行全部运行结束 - 如果没有主动停止，如果有任务还在执行中会一直等待
@@ -62,7 +195,7 @@ func (p *TimeoutPool) Submit(job *Job) {
 // 注意：最终应该只有一个协程来调用Wait等待协程池运行结束，否则其中的计数存在竞态条件问题
 func (p *TimeoutPool) StartAndWaitUntilTerminated() bool {
 	// 启动协程池
-	p.start()
+	p.startOnce.Do(p.start)
 
 	// 等待运行结束
 	completed := 0
@@ -88,7 +221,7 @@ func (p *TimeoutPool) StartAndWaitUntilTerminated() bool {
 // 注意：最终应该只有一个协程来调用Wait等待协程池运行结束，否则其中的计数存在竞态条件问题
 func (p *TimeoutPool) StartAndWait(timeout time.Duration) bool {
 	// 启动协程池
-	p.start()
+	p.startOnce.Do(p.start)
 
 	// 等待运行结束
 	completed := 0
@@ -113,8 +246,54 @@ func (p *TimeoutPool) StartAndWait(timeout time.Duration) bool {
 	return true
 }
 
+// Done 返回一个 channel，当前已提交的任务全部执行完毕时关闭，没有未完成的任务时返回已关闭的 channel
+// 全部完成后再提交的任务需要重新调用 Done 获取新的 channel；协程池停止不会关闭该 channel
+// 注意：协程池需已启动，可配合 select 与 ctx.Done() 实现自定义的等待
+func (p *TimeoutPool) Done() <-chan struct{} {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.idle == nil {
+		p.idle = make(chan struct{})
+		if p.unfinished == 0 {
+			close(p.idle)
+		}
+	}
+	return p.idle
+}
+
 // ~~ 内部实现
 
+// accept 登记一个待提交的任务，协程池已关闭时返回 false
+func (p *TimeoutPool) accept() bool {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.closed {
+		return false
+	}
+	p.jobCount++
+	p.pending.Add(1)
+	if p.unfinished++; p.unfinished == 1 {
+		p.idle = nil // 新一批任务，Done 需返回新的 channel
+	}
+	return true
+}
+
+// reject 撤销未能提交的任务
+func (p *TimeoutPool) reject() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.jobCount--
+	p.finish()
+}
+
+// finish 标记一个任务执行完毕或撤销，调用方需持有锁
+func (p *TimeoutPool) finish() {
+	p.pending.Done()
+	if p.unfinished--; p.unfinished == 0 && p.idle != nil {
+		close(p.idle)
+	}
+}
+
 func (p *TimeoutPool) start() {
 	for i := 0; i < cap(p.workerQueue); i++ {
 		newWorker(p.workerQueue, p.jobRet)
@@ -128,8 +307,16 @@ func (p *TimeoutPool) dispatch() {
 		var job *Job
 		select {
 		case job = <-p.jobQueue:
+			handler := job.Handler
 			worker := <-p.workerQueue
-			worker.jobChannel <- *job
+			worker.jobChannel <- Job{Handler: func(v ...interface{}) {
+				defer func() {
+					p.lock.Lock()
+					defer p.lock.Unlock()
+					p.finish()
+				}()
+				handler(v...)
+			}, Params: job.Params}
 		case <-p.stop:
 			for i := 0; i < cap(p.workerQueue); i++ {
 				worker := <-p.workerQueue
@@ -1,7 +1,9 @@
 package thread
 
 import (
+	"context"
 	"fmt"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -21,3 +23,184 @@ func TestNewPool(t *testing.T) {
 	pool.StartAndWait(time.Second * 5)
 	fmt.Println("finished")
 }
+
+func TestTimeoutPool_SubmitWithDeadline(t *testing.T) {
+	pool := NewTimeoutPool(1, 2)
+	job := &Job{Handler: func(v ...interface{}) {}}
+
+	for i := 0; i < 2; i++ {
+		if err := pool.SubmitWithDeadline(job, time.Now().Add(time.Second)); err != nil {
+			t.Fatalf("submit job to idle pool fail: %s", err)
+		}
+	}
+
+	start := time.Now()
+	if err := pool.SubmitWithDeadline(job, time.Now().Add(20*time.Millisecond)); err != ErrDeadlineExceeded {
+		t.Errorf("expect ErrDeadlineExceeded when queue is full, got: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("submit should not block after deadline, took %s", elapsed)
+	}
+	if err := pool.SubmitWithDeadline(job, time.Now()); err != ErrDeadlineExceeded {
+		t.Errorf("expect ErrDeadlineExceeded with passed deadline, got: %v", err)
+	}
+
+	if !pool.StartAndWait(time.Second) {
+		t.Errorf("submitted jobs should be finished")
+	}
+}
+
+func TestTimeoutPool_SubmitContextJob(t *testing.T) {
+	pool := NewTimeoutPool(2, 10)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	result := make(chan error, 1)
+	err := pool.SubmitContextJob(&ContextJob{
+		Ctx: ctx,
+		Handler: func(ctx context.Context, v ...interface{}) {
+			select {
+			case <-ctx.Done():
+				result <- ctx.Err()
+			case <-time.After(v[0].(time.Duration)):
+				result <- nil
+			}
+		},
+		Params: []interface{}{10 * time.Second},
+	})
+	if err != nil {
+		t.Fatalf("submit context job fail: %s", err)
+	}
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+	if !pool.StartAndWait(time.Second) {
+		t.Errorf("cancelled job should finish in time")
+	}
+	if err := <-result; err != context.Canceled {
+		t.Errorf("expect job cancelled, got: %v", err)
+	}
+
+	if err := pool.SubmitContextJob(&ContextJob{Ctx: ctx, Handler: func(context.Context, ...interface{}) {}}); err != context.Canceled {
+		t.Errorf("expect submit with cancelled context rejected, got: %v", err)
+	}
+}
+
+func TestTimeoutPool_DrainAndClose(t *testing.T) {
+	pool := NewTimeoutPool(4, 200)
+	var completed int32
+	for i := 0; i < 100; i++ {
+		pool.Submit(&Job{Handler: func(v ...interface{}) {
+			time.Sleep(time.Millisecond)
+			atomic.AddInt32(&completed, 1)
+		}})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := pool.DrainAndClose(ctx); err != nil {
+		t.Fatalf("drain fail: %s", err)
+	}
+	if n := atomic.LoadInt32(&completed); n != 100 {
+		t.Errorf("expect 100 jobs completed, got %d", n)
+	}
+
+	job := &Job{Handler: func(v ...interface{}) { atomic.AddInt32(&completed, 1) }}
+	pool.Submit(job)
+	if err := pool.SubmitWithDeadline(job, time.Now().Add(time.Second)); err != ErrPoolClosed {
+		t.Errorf("expect ErrPoolClosed after close, got: %v", err)
+	}
+	if n := atomic.LoadInt32(&completed); n != 100 {
+		t.Errorf("job submitted after close should not run")
+	}
+}
+
+func TestTimeoutPool_DrainAndClose_deadline(t *testing.T) {
+	pool := NewTimeoutPool(1, 10)
+	release := make(chan struct{})
+	for i := 0; i < 3; i++ {
+		pool.Submit(&Job{Handler: func(v ...interface{}) { <-release }})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := pool.DrainAndClose(ctx); err != context.DeadlineExceeded {
+		t.Errorf("expect deadline exceeded, got: %v", err)
+	}
+	close(release)
+}
+
+func TestTimeoutPool_DrainAndClose_repeat(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	pool := NewTimeoutPool(2, 10)
+	pool.Submit(&Job{Handler: func(v ...interface{}) {}})
+	for i := 0; i < 2; i++ {
+		if err := pool.DrainAndClose(ctx); err != nil {
+			t.Fatalf("drain #%d fail: %s", i+1, err)
+		}
+	}
+	pool.Terminate()
+
+	// 已停止的协程池直接返回
+	pool = NewTimeoutPool(1, 10)
+	release := make(chan struct{})
+	defer close(release)
+	pool.Submit(&Job{Handler: func(v ...interface{}) { <-release }})
+	pool.Submit(&Job{Handler: func(v ...interface{}) {}})
+	go pool.StartAndWaitUntilTerminated()
+	time.Sleep(10 * time.Millisecond)
+	go pool.Terminate()
+	time.Sleep(10 * time.Millisecond)
+	if err := pool.DrainAndClose(ctx); err != nil {
+		t.Errorf("expect drain of terminated pool returns immediately, got: %v", err)
+	}
+}
+
+func TestTimeoutPool_Done(t *testing.T) {
+	pool := NewTimeoutPool(4, 100)
+	select {
+	case <-pool.Done():
+	default:
+		t.Fatal("expect Done closed without submitted jobs")
+	}
+
+	var completed int32
+	release := make(chan struct{})
+	for i := 0; i < 10; i++ {
+		pool.Submit(&Job{Handler: func(v ...interface{}) {
+			if v[0].(int) == 9 {
+				<-release
+			}
+			atomic.AddInt32(&completed, 1)
+		}, Params: []interface{}{i}})
+	}
+	done := pool.Done()
+	go pool.StartAndWaitUntilTerminated()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	select {
+	case <-done:
+		t.Fatal("Done closed before last job finished")
+	case <-ctx.Done():
+	}
+	if n := atomic.LoadInt32(&completed); n != 9 {
+		t.Errorf("expect 9 jobs completed, got %d", n)
+	}
+
+	close(release)
+	ctx, cancel = context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		t.Fatal("Done not closed after all jobs finished")
+	}
+	if n := atomic.LoadInt32(&completed); n != 10 {
+		t.Errorf("expect 10 jobs completed, got %d", n)
+	}
+	pool.Terminate()
+}
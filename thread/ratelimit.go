@@ -0,0 +1,54 @@
+package thread
+
+import (
+	"context"
+
+	"golang.org/x/time/rate"
+)
+
+// NewRateLimitedPool 初始化一个限制任务提交速率的协程池，每秒最多提交 maxJobsPerSecond 个任务，避免突发提交压垮任务队列
+func NewRateLimitedPool(workers, queueCap int, maxJobsPerSecond float64) *RateLimitedPool {
+	return &RateLimitedPool{
+		TimeoutPool: NewTimeoutPool(workers, queueCap),
+		limiter:     rate.NewLimiter(rate.Limit(maxJobsPerSecond), 1),
+	}
+}
+
+// RateLimitedPool 限制任务提交速率的协程池
+type RateLimitedPool struct {
+	*TimeoutPool
+
+	limiter *rate.Limiter
+}
+
+// Submit 提交一个任务到协程池，阻塞直至提交速率允许，协程池关闭后提交的任务会被丢弃
+func (p *RateLimitedPool) Submit(job *Job) {
+	_ = p.limiter.Wait(context.Background())
+	p.TimeoutPool.Submit(job)
+}
+
+// SubmitAsync 非阻塞提交任务，提交结果通过返回的 channel 通知：
+// 成功为 nil，超过提交速率返回 ErrRateLimited，任务队列已满返回 ErrQueueFull，协程池已关闭返回 ErrPoolClosed
+func (p *RateLimitedPool) SubmitAsync(job *Job) <-chan error {
+	ret := make(chan error, 1)
+	ret <- p.trySubmit(job)
+	close(ret)
+	return ret
+}
+
+func (p *RateLimitedPool) trySubmit(job *Job) error {
+	if !p.limiter.Allow() {
+		return ErrRateLimited
+	}
+	if !p.accept() {
+		return ErrPoolClosed
+	}
+
+	select {
+	case p.jobQueue <- job:
+		return nil
+	default:
+		p.reject()
+		return ErrQueueFull
+	}
+}
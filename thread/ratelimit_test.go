@@ -0,0 +1,59 @@
+package thread
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRateLimitedPool_Submit(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skip rate limited submission in short mode")
+	}
+
+	pool := NewRateLimitedPool(10, 1000, 100)
+
+	var done int64
+	start := time.Now()
+	for i := 0; i < 1000; i++ {
+		pool.Submit(&Job{Handler: func(v ...interface{}) { atomic.AddInt64(&done, 1) }})
+	}
+	if !pool.StartAndWait(5 * time.Second) {
+		t.Fatal("submitted jobs should be finished")
+	}
+	elapsed := time.Since(start)
+
+	if done != 1000 {
+		t.Errorf("expect 1000 jobs done, got %d", done)
+	}
+	if elapsed < 9*time.Second || elapsed > 12*time.Second {
+		t.Errorf("expect 1000 jobs at 100/s done in about 10s, took %s", elapsed)
+	}
+}
+
+func TestRateLimitedPool_SubmitAsync(t *testing.T) {
+	job := &Job{Handler: func(v ...interface{}) {}}
+
+	pool := NewRateLimitedPool(1, 10, 1)
+	if err := <-pool.SubmitAsync(job); err != nil {
+		t.Fatalf("submit job to idle pool fail: %s", err)
+	}
+	if err := <-pool.SubmitAsync(job); err != ErrRateLimited {
+		t.Errorf("expect ErrRateLimited when rate exceeded, got: %v", err)
+	}
+	if !pool.StartAndWait(time.Second) {
+		t.Errorf("submitted jobs should be finished")
+	}
+
+	pool = NewRateLimitedPool(1, 1, 1000)
+	if err := <-pool.SubmitAsync(job); err != nil {
+		t.Fatalf("submit job to idle pool fail: %s", err)
+	}
+	time.Sleep(10 * time.Millisecond)
+	if err := <-pool.SubmitAsync(job); err != ErrQueueFull {
+		t.Errorf("expect ErrQueueFull when queue is full, got: %v", err)
+	}
+	if !pool.StartAndWait(time.Second) {
+		t.Errorf("submitted jobs should be finished")
+	}
+}
@@ -0,0 +1,221 @@
+package thread
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	stealBatch   = 4                // max jobs a worker moves from global queue into its local queue at once
+	idleInterval = time.Millisecond // interval idle worker retries stealing
+)
+
+// NewWorkStealingPool 初始化一个工作窃取协程池，每个 worker 拥有私有的本地队列，
+// 本地队列为空时从全局队列批量获取任务，全局队列也为空时从其他 worker 本地队列尾部窃取任务
+func NewWorkStealingPool(workers, localQueueCap, globalQueueCap int) *WorkStealingPool {
+	p := &WorkStealingPool{
+		global: make(chan *Job, globalQueueCap),
+		stop:   make(chan struct{}),
+	}
+	for i := 0; i < workers; i++ {
+		p.workers = append(p.workers, newDeque(localQueueCap))
+	}
+	for i := range p.workers {
+		p.running.Add(1)
+		go p.work(i)
+	}
+	return p
+}
+
+// WorkStealingPool 工作窃取协程池
+type WorkStealingPool struct {
+	workers []*deque
+	global  chan *Job
+
+	pending sync.WaitGroup // submitted jobs not finished
+	running sync.WaitGroup // running workers
+
+	mu       sync.RWMutex // Stop 持有写锁，等待进行中的 Submit 结束
+	stopped  bool
+	stopOnce sync.Once
+	stop     chan struct{}
+}
+
+// Submit 提交一个任务到协程池，全局队列已满时阻塞，协程池停止后提交的任务被丢弃
+func (p *WorkStealingPool) Submit(job *Job) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.stopped {
+		return
+	}
+
+	p.pending.Add(1)
+	select {
+	case p.global <- job:
+	case <-p.stop:
+		p.pending.Done()
+	}
+}
+
+// Wait 等待所有已提交任务执行完毕
+func (p *WorkStealingPool) Wait() { p.pending.Wait() }
+
+// Stop 停止协程池，等待正在执行的任务结束，未执行的任务被丢弃
+func (p *WorkStealingPool) Stop() {
+	p.stopOnce.Do(func() {
+		close(p.stop)
+
+		p.mu.Lock()
+		p.stopped = true
+		p.mu.Unlock()
+	})
+	p.running.Wait()
+	p.drain()
+}
+
+// drain 丢弃队列中未执行的任务，需在所有 worker 退出后调用
+func (p *WorkStealingPool) drain() {
+	for _, local := range p.workers {
+		for local.pop() != nil {
+			p.pending.Done()
+		}
+	}
+	for {
+		select {
+		case <-p.global:
+			p.pending.Done()
+		default:
+			return
+		}
+	}
+}
+
+func (p *WorkStealingPool) work(id int) {
+	defer p.running.Done()
+
+	local := p.workers[id]
+	idle := time.NewTimer(idleInterval)
+	defer idle.Stop()
+	for {
+		job := local.pop()
+		if job == nil {
+			job = p.fetchGlobal(local)
+		}
+		if job == nil {
+			job = p.steal(id)
+		}
+		if job == nil { // wait for new job, retry stealing periodically
+			idle.Reset(idleInterval)
+			select {
+			case job = <-p.global:
+			case <-idle.C:
+				continue
+			case <-p.stop:
+				return
+			}
+		}
+
+		select {
+		case <-p.stop:
+			p.pending.Done() // job dropped
+			return
+		default:
+		}
+		job.Handler(job.Params...)
+		p.pending.Done()
+	}
+}
+
+// fetchGlobal take one job from global queue to run, and move a batch into local queue
+func (p *WorkStealingPool) fetchGlobal(local *deque) *Job {
+	var job *Job
+	select {
+	case job = <-p.global:
+	default:
+		return nil
+	}
+
+	for i := 0; i < stealBatch && !local.full(); i++ {
+		select {
+		case next := <-p.global:
+			local.push(next)
+		default:
+			return job
+		}
+	}
+	return job
+}
+
+// steal steal job from other workers, starting from the next one
+func (p *WorkStealingPool) steal(id int) *Job {
+	for i := 1; i < len(p.workers); i++ {
+		if job := p.workers[(id+i)%len(p.workers)].steal(); job != nil {
+			return job
+		}
+	}
+	return nil
+}
+
+// deque bounded lock-free work-stealing deque (Chase-Lev),
+// only owner pushes and pops at bottom, thieves steal from top
+type deque struct {
+	top    atomic.Int64
+	bottom atomic.Int64
+	buf    []atomic.Pointer[Job]
+	mask   int64
+}
+
+func newDeque(capacity int) *deque {
+	size := 1
+	for size < capacity {
+		size <<= 1
+	}
+	return &deque{buf: make([]atomic.Pointer[Job], size), mask: int64(size - 1)}
+}
+
+func (d *deque) full() bool { return d.bottom.Load()-d.top.Load() >= int64(len(d.buf)) }
+
+// push push job at bottom, return false if full, called by owner only
+func (d *deque) push(job *Job) bool {
+	b := d.bottom.Load()
+	if b-d.top.Load() >= int64(len(d.buf)) {
+		return false
+	}
+	d.buf[b&d.mask].Store(job)
+	d.bottom.Store(b + 1)
+	return true
+}
+
+// pop pop job at bottom, return nil if empty, called by owner only
+func (d *deque) pop() *Job {
+	b := d.bottom.Load() - 1
+	d.bottom.Store(b)
+	t := d.top.Load()
+	if t > b { // empty
+		d.bottom.Store(t)
+		return nil
+	}
+
+	job := d.buf[b&d.mask].Load()
+	if t == b { // last job, race with thieves
+		if !d.top.CompareAndSwap(t, t+1) {
+			job = nil
+		}
+		d.bottom.Store(t + 1)
+	}
+	return job
+}
+
+// steal steal job at top, return nil if empty or lost race
+func (d *deque) steal() *Job {
+	t := d.top.Load()
+	if t >= d.bottom.Load() {
+		return nil
+	}
+	job := d.buf[t&d.mask].Load()
+	if !d.top.CompareAndSwap(t, t+1) {
+		return nil
+	}
+	return job
+}
@@ -0,0 +1,135 @@
+package thread
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWorkStealingPool(t *testing.T) {
+	pool := NewWorkStealingPool(4, 16, 64)
+	defer pool.Stop()
+
+	var sum int64
+	for i := 1; i <= 1000; i++ {
+		pool.Submit(&Job{
+			Handler: func(v ...interface{}) { atomic.AddInt64(&sum, int64(v[0].(int))) },
+			Params:  []interface{}{i},
+		})
+	}
+	pool.Wait()
+
+	if sum != 500500 {
+		t.Errorf("expect sum 500500, got %d", sum)
+	}
+}
+
+func TestWorkStealingPool_steal(t *testing.T) {
+	// every job of first batch blocks until all workers are busy, only possible when jobs are stolen
+	const workers = 4
+	pool := NewWorkStealingPool(workers, 64, 64)
+	defer pool.Stop()
+
+	var started sync.WaitGroup
+	started.Add(workers)
+	for i := 0; i < workers; i++ {
+		pool.Submit(&Job{Handler: func(...interface{}) {
+			started.Done()
+			started.Wait()
+		}})
+	}
+
+	done := make(chan struct{})
+	go func() { pool.Wait(); close(done) }()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("jobs not run concurrently")
+	}
+}
+
+func TestWorkStealingPool_Stop(t *testing.T) {
+	pool := NewWorkStealingPool(1, 4, 8)
+
+	var executed int32
+	started, release := make(chan struct{}), make(chan struct{})
+	pool.Submit(&Job{Handler: func(v ...interface{}) {
+		close(started)
+		<-release
+		atomic.AddInt32(&executed, 1)
+	}})
+	<-started
+	for i := 0; i < 8; i++ {
+		pool.Submit(&Job{Handler: func(v ...interface{}) { atomic.AddInt32(&executed, 1) }})
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		pool.Stop()
+		close(stopped)
+	}()
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+
+	done := make(chan struct{})
+	go func() {
+		<-stopped
+		pool.Wait()
+		for i := 0; i < 20; i++ { // global queue capacity is 8, submit after stop should not block
+			pool.Submit(&Job{Handler: func(v ...interface{}) { atomic.AddInt32(&executed, 1) }})
+		}
+		pool.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Wait or Submit blocked after Stop")
+	}
+	if n := atomic.LoadInt32(&executed); n != 1 {
+		t.Errorf("expect only running job executed, got %d", n)
+	}
+}
+
+func Test_deque(t *testing.T) {
+	d := newDeque(3)
+	jobs := make([]*Job, 5)
+	for i := range jobs {
+		jobs[i] = new(Job)
+	}
+	for i, job := range jobs {
+		if ok := d.push(job); ok != (i < 4) {
+			t.Errorf("push %d: expect %t, got %t", i, i < 4, ok)
+		}
+	}
+	if d.steal() != jobs[0] || d.pop() != jobs[3] || d.pop() != jobs[2] || d.steal() != jobs[1] {
+		t.Errorf("unexpected deque order")
+	}
+	if d.pop() != nil || d.steal() != nil {
+		t.Errorf("expect empty deque")
+	}
+}
+
+// benchmark skewed workload: one of every 16 jobs is much heavier than others
+func benchmarkSkewed(b *testing.B, submit func(*Job), wait func()) {
+	for i := 0; i < b.N; i++ {
+		cost := 10 * time.Microsecond
+		if i%16 == 0 {
+			cost = time.Millisecond
+		}
+		submit(&Job{Handler: func(v ...interface{}) { time.Sleep(v[0].(time.Duration)) }, Params: []interface{}{cost}})
+	}
+	wait()
+}
+
+func BenchmarkWorkStealingPool_skewed(b *testing.B) {
+	pool := NewWorkStealingPool(defaultWorkerQueueLength, 256, b.N)
+	defer pool.Stop()
+	benchmarkSkewed(b, pool.Submit, pool.Wait)
+}
+
+func BenchmarkTimeoutPool_skewed(b *testing.B) {
+	pool := NewTimeoutPool(defaultWorkerQueueLength, b.N)
+	benchmarkSkewed(b, pool.Submit, func() { pool.StartAndWaitUntilTerminated() })
+}